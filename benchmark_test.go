@@ -41,6 +41,64 @@ func BenchmarkParseWithFragment(b *testing.B) {
 	parsed = p
 }
 
+func BenchmarkParseBytes(b *testing.B) {
+	var p *did.DID
+	input := []byte("did:ockam:amzbjdl8etgpgwoe841sfi6fc4q9yh82m6pkmkw5pteabvtzm7p6qe106ysiawmo")
+	for n := 0; n < b.N; n++ {
+		p, _ = did.ParseBytes(input)
+	}
+	parsed = p
+}
+
+// ParseInto and Validate should not allocate
+
+func BenchmarkParseInto(b *testing.B) {
+	b.ReportAllocs()
+	p := &did.DID{}
+	for n := 0; n < b.N; n++ {
+		_ = did.ParseInto(p, "did:ockam:amzbjdl8etgpgwoe841sfi6fc4q9yh82m6pkmkw5pteabvtzm7p6qe106ysiawmo")
+	}
+	parsed = p
+}
+
+func BenchmarkParseIntoWithPath(b *testing.B) {
+	b.ReportAllocs()
+	p := &did.DID{}
+	for n := 0; n < b.N; n++ {
+		_ = did.ParseInto(p, "did:ockam:amzbjdl8etgpgwoe841sfi6fc4q9yh82/6pkmkw5pteabvtzm7p6qe106ysiawmo")
+	}
+	parsed = p
+}
+
+func BenchmarkParseIntoWithQuery(b *testing.B) {
+	b.ReportAllocs()
+	p := &did.DID{}
+	for n := 0; n < b.N; n++ {
+		_ = did.ParseInto(p, "did:ockam:amzbjdl8etgpgwoe841sfi6fc4q9yh82?6pkmkw5pteabvtzm7p6qe106ysiawmo")
+	}
+	parsed = p
+}
+
+func BenchmarkParseIntoWithFragment(b *testing.B) {
+	b.ReportAllocs()
+	p := &did.DID{}
+	for n := 0; n < b.N; n++ {
+		_ = did.ParseInto(p, "did:ockam:amzbjdl8etgpgwoe841sfi6fc4q9yh82#6pkmkw5pteabvtzm7p6qe106ysiawmo")
+	}
+	parsed = p
+}
+
+var validated error
+
+func BenchmarkValidate(b *testing.B) {
+	b.ReportAllocs()
+	var err error
+	for n := 0; n < b.N; n++ {
+		err = did.Validate("did:ockam:amzbjdl8etgpgwoe841sfi6fc4q9yh82/6pkmkw5pteabvtzm7p6qe106ysiawmo?a=b#c")
+	}
+	validated = err
+}
+
 // Sanity check against Go's URL parsing to make sure we're in the same order of magnitude
 
 var parsedURL *url.URL
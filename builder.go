@@ -0,0 +1,176 @@
+package did
+
+import (
+	"strings"
+)
+
+// New returns a DID with the given method and idstrings, which are joined with `:` to make the
+// method-specific-id. The method must be made of a-z and 0-9, the idstrings must be made of idchars or
+// pct-encoded octets and only the last idstring must not be empty. If the DID would not be valid,
+// New returns a *ParseError for the method or the idstring that is not valid.
+// from the grammar:
+//   did                = "did:" method-name ":" method-specific-id
+//   method-specific-id = *( *idchar ":" ) 1*idchar
+func New(method string, idstrings ...string) (*DID, error) {
+	if err := checkMethod(method); err != nil {
+		return nil, err
+	}
+
+	if len(idstrings) == 0 || idstrings[len(idstrings)-1] == "" {
+		return nil, newParseError("", 0, ComponentMethodSpecificID, "method-specific-id", ErrEmptyIDString,
+			"last idstring must be atleast one char long")
+	}
+	for _, idstring := range idstrings {
+		// isNotValidIDChar rejects `:`, which would split the idstring in two
+		err := checkChars(idstring, ComponentMethodSpecificID, "idchar", isNotValidIDChar)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	d := &DID{Method: method, IDStrings: append([]string(nil), idstrings...)}
+	d.ID = strings.Join(d.IDStrings, ":")
+
+	return d, nil
+}
+
+// WithPath returns a copy of d with its Path replaced by path, a leading `/` is optional.
+// The path is made of `/` separated segments of pchars or pct-encoded octets,
+// an empty path returns a copy of d without a path.
+// from the grammar:
+//   path-abempty = *( "/" segment )
+//   segment      = *pchar
+func (d *DID) WithPath(path string) (*DID, error) {
+	path = strings.TrimPrefix(path, "/")
+
+	// `/` separates segments, so it is the only char that is allowed in a path but not in a segment
+	err := checkChars(path, ComponentPath, "pchar", func(char byte) bool {
+		return char != '/' && isNotValidPathChar(char)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c := d.clone()
+	c.Path = path
+	c.PathSegments = nil
+	if path != "" {
+		c.PathSegments = strings.Split(path, "/")
+	}
+
+	return c, nil
+}
+
+// WithQuery returns a copy of d with its Query replaced by query, which must be made of pchars,
+// `/`, `?` or pct-encoded octets. An empty query returns a copy of d without a query.
+// from the grammar:
+//   query = *( pchar / "/" / "?" )
+func (d *DID) WithQuery(query string) (*DID, error) {
+	err := checkChars(query, ComponentQuery, "query", isNotValidQueryOrFragmentChar)
+	if err != nil {
+		return nil, err
+	}
+
+	c := d.clone()
+	c.Query = query
+
+	return c, nil
+}
+
+// WithParam returns a copy of d with the DID parameter name=value appended to its Query.
+// DID Core 1.0 carries DID parameters in the query, name and value are not encoded and are
+// percent encoded as needed, ex- WithParam("service", "files") appends `service=files`.
+// https://www.w3.org/TR/did-core/#did-parameters
+func (d *DID) WithParam(name, value string) (*DID, error) {
+	if name == "" {
+		return nil, newParseError(name, 0, ComponentQuery, "param-name", ErrEmptyParamName,
+			"param name must be atleast one char long")
+	}
+
+	c := d.clone()
+	if c.Query != "" {
+		c.Query = c.Query + "&"
+	}
+	c.Query = c.Query + EscapeQueryComponent(name) + "=" + EscapeQueryComponent(value)
+
+	return c, nil
+}
+
+// WithFragment returns a copy of d with its Fragment replaced by fragment, which must be made of pchars,
+// `/`, `?` or pct-encoded octets. An empty fragment returns a copy of d without a fragment.
+// from the grammar:
+//   fragment = *( pchar / "/" / "?" )
+func (d *DID) WithFragment(fragment string) (*DID, error) {
+	err := checkChars(fragment, ComponentFragment, "fragment", isNotValidQueryOrFragmentChar)
+	if err != nil {
+		return nil, err
+	}
+
+	c := d.clone()
+	c.Fragment = fragment
+
+	return c, nil
+}
+
+// WithoutFragment returns a copy of d without a Fragment
+func (d *DID) WithoutFragment() *DID {
+	c := d.clone()
+	c.Fragment = ""
+	return c
+}
+
+// Base returns a copy of d without Params, Path, Query and Fragment, i.e. the DID that a DID URL refers to
+func (d *DID) Base() *DID {
+	return &DID{Method: d.Method, ID: d.ID, IDStrings: append([]string(nil), d.IDStrings...)}
+}
+
+// clone returns a copy of d that does not share its slices with d
+func (d *DID) clone() *DID {
+	c := *d
+	c.IDStrings = append([]string(nil), d.IDStrings...)
+	c.Params = append([]Param(nil), d.Params...)
+	c.PathSegments = append([]string(nil), d.PathSegments...)
+	return &c
+}
+
+// checkMethod returns a *ParseError if method is not a valid method name
+// from the grammar:
+//   method-name = 1*method-char
+//   method-char = %x61-7A / DIGIT
+func checkMethod(method string) error {
+	if method == "" {
+		return newParseError(method, 0, ComponentMethod, "method-name", ErrMissingMethod, "method is empty")
+	}
+	for i := 0; i < len(method); i++ {
+		if isNotDigit(method[i]) && isNotSmallLetter(method[i]) {
+			return newParseError(method, i, ComponentMethod, "method-char", ErrInvalidCharacter,
+				"character is not a-z OR 0-9")
+		}
+	}
+	return nil
+}
+
+// checkChars returns a *ParseError if s, which is a part of the given component, contains a byte for which
+// isNotValid returns true, or a `%` that is not followed by 2 hex digits
+func checkChars(s string, component Component, rule string, isNotValid func(byte) bool) error {
+	for i := 0; i < len(s); i++ {
+		char := s[i]
+
+		if char == '%' {
+			// a % must be followed by 2 hex digits
+			if i+2 >= len(s) || isNotHexDigit(s[i+1]) || isNotHexDigit(s[i+2]) {
+				return newParseError(s, i, component, "pct-encoded", ErrInvalidPercentEncoding,
+					"%% is not followed by 2 hex digits")
+			}
+			i = i + 2
+			continue
+		}
+
+		if isNotValid(char) {
+			return newParseError(s, i, component, rule, ErrInvalidCharacter,
+				"character is not allowed in %s - %c", component, char)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package did
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("joins idstrings into the method-specific-id", func(t *testing.T) {
+		d, err := New("example", "", "123", "a%20b")
+		assert(t, nil, err)
+		assert(t, ":123:a%20b", d.ID)
+		assert(t, []string{"", "123", "a%20b"}, d.IDStrings)
+		assert(t, "did:example::123:a%20b", d.String())
+
+		parsed, err := Parse(d.String())
+		assert(t, nil, err)
+		assert(t, d, parsed)
+	})
+
+	t.Run("does not retain the idstrings slice", func(t *testing.T) {
+		idstrings := []string{"123"}
+		d, err := New("example", idstrings...)
+		assert(t, nil, err)
+		idstrings[0] = "456"
+		assert(t, []string{"123"}, d.IDStrings)
+	})
+
+	tests := []struct {
+		method    string
+		idstrings []string
+		offset    int
+		component Component
+		err       error
+	}{
+		{"", []string{"123"}, 0, ComponentMethod, ErrMissingMethod},
+		{"exAmple", []string{"123"}, 2, ComponentMethod, ErrInvalidCharacter},
+		{"example", nil, 0, ComponentMethodSpecificID, ErrEmptyIDString},
+		{"example", []string{"123", ""}, 0, ComponentMethodSpecificID, ErrEmptyIDString},
+		{"example", []string{"1:3"}, 1, ComponentMethodSpecificID, ErrInvalidCharacter},
+		{"example", []string{"123", "4/6"}, 1, ComponentMethodSpecificID, ErrInvalidCharacter},
+		{"example", []string{"12%3"}, 2, ComponentMethodSpecificID, ErrInvalidPercentEncoding},
+	}
+
+	for _, test := range tests {
+		d, err := New(test.method, test.idstrings...)
+		assert(t, true, d == nil, "Input: %s %v", test.method, test.idstrings)
+
+		var pe *ParseError
+		assert(t, true, errors.As(err, &pe), "Input: %s %v", test.method, test.idstrings)
+		assert(t, true, errors.Is(err, test.err), "Input: %s %v", test.method, test.idstrings)
+		assert(t, test.offset, pe.Offset, "Input: %s %v", test.method, test.idstrings)
+		assert(t, test.component, pe.Component, "Input: %s %v", test.method, test.idstrings)
+	}
+}
+
+func TestWith(t *testing.T) {
+	base, err := New("example", "123")
+	assert(t, nil, err)
+
+	t.Run("builds a DID URL without changing the DID it was built from", func(t *testing.T) {
+		d, err := base.WithPath("/a/b")
+		assert(t, nil, err)
+		d, err = d.WithQuery("versionId=1")
+		assert(t, nil, err)
+		d, err = d.WithParam("service", "a b&c")
+		assert(t, nil, err)
+		d, err = d.WithFragment("key-1")
+		assert(t, nil, err)
+
+		assert(t, "did:example:123/a/b?versionId=1&service=a%20b%26c#key-1", d.String())
+		assert(t, []string{"a", "b"}, d.PathSegments)
+		assert(t, "did:example:123", base.String())
+
+		parsed, err := Parse(d.String())
+		assert(t, nil, err)
+		assert(t, d, parsed)
+
+		assert(t, "did:example:123/a/b?versionId=1&service=a%20b%26c", d.WithoutFragment().String())
+		assert(t, "did:example:123/a/b?versionId=1&service=a%20b%26c#key-1", d.String())
+		assert(t, base, d.Base())
+	})
+
+	t.Run("an empty component is removed", func(t *testing.T) {
+		d := &DID{Method: "example", ID: "123", Path: "a", PathSegments: []string{"a"}, Query: "b", Fragment: "c"}
+
+		d, err := d.WithPath("")
+		assert(t, nil, err)
+		d, err = d.WithQuery("")
+		assert(t, nil, err)
+		d, err = d.WithFragment("")
+		assert(t, nil, err)
+		assert(t, "did:example:123", d.String())
+		assert(t, []string(nil), d.PathSegments)
+	})
+
+	t.Run("copies do not share slices", func(t *testing.T) {
+		d, err := base.WithPath("a/b")
+		assert(t, nil, err)
+		c := d.WithoutFragment()
+		c.PathSegments[0] = "x"
+		c.IDStrings[0] = "x"
+		assert(t, []string{"a", "b"}, d.PathSegments)
+		assert(t, []string{"123"}, d.IDStrings)
+	})
+
+	tests := []struct {
+		name      string
+		with      func() (*DID, error)
+		offset    int
+		component Component
+		err       error
+	}{
+		{"path", func() (*DID, error) { return base.WithPath("a/b c") }, 3, ComponentPath, ErrInvalidCharacter},
+		{"path", func() (*DID, error) { return base.WithPath("a?b") }, 1, ComponentPath, ErrInvalidCharacter},
+		{"path", func() (*DID, error) { return base.WithPath("a%2") }, 1, ComponentPath, ErrInvalidPercentEncoding},
+		{"query", func() (*DID, error) { return base.WithQuery("a#b") }, 1, ComponentQuery, ErrInvalidCharacter},
+		{"param", func() (*DID, error) { return base.WithParam("", "b") }, 0, ComponentQuery, ErrEmptyParamName},
+		{"fragment", func() (*DID, error) { return base.WithFragment("a#b") }, 1, ComponentFragment, ErrInvalidCharacter},
+		{"fragment", func() (*DID, error) { return base.WithFragment("%zz") }, 0, ComponentFragment,
+			ErrInvalidPercentEncoding},
+	}
+
+	for _, test := range tests {
+		d, err := test.with()
+		assert(t, true, d == nil, test.name)
+
+		var pe *ParseError
+		assert(t, true, errors.As(err, &pe), test.name)
+		assert(t, true, errors.Is(err, test.err), test.name)
+		assert(t, test.offset, pe.Offset, test.name)
+		assert(t, test.component, pe.Component, test.name)
+	}
+}
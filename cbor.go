@@ -0,0 +1,522 @@
+package did
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"unicode/utf8"
+)
+
+// MediaTypeCBOR is the media type of the CBOR representation of a DID document
+// https://www.w3.org/TR/2020/WD-did-core-20200731/#cbor
+const MediaTypeCBOR = "application/did+cbor"
+
+// ErrInvalidCBOR is returned when the CBOR representation of a DID document is not well-formed,
+// uses a CBOR feature that has no equivalent in a DID document, like a tag, or is not deterministically
+// encoded when it is strictly decoded
+var ErrInvalidCBOR = errors.New("invalid CBOR")
+
+// maxCBORDepth is the number of arrays and maps that may be nested in one another in a CBOR representation
+const maxCBORDepth = 256
+
+// The major types of CBOR
+// https://www.rfc-editor.org/rfc/rfc8949#section-3.1
+const (
+	cborUnsigned = 0 << 5
+	cborNegative = 1 << 5
+	cborBytes    = 2 << 5
+	cborText     = 3 << 5
+	cborArray    = 4 << 5
+	cborMap      = 5 << 5
+	cborTag      = 6 << 5
+	cborSimple   = 7 << 5
+)
+
+// The simple values and the additional information of major type 7 that are used in a DID document
+const (
+	cborFalse      = cborSimple | 20
+	cborTrue       = cborSimple | 21
+	cborNull       = cborSimple | 22
+	cborFloat16    = cborSimple | 25
+	cborFloat32    = cborSimple | 26
+	cborFloat64    = cborSimple | 27
+	cborBreak      = cborSimple | 31
+	cborIndefinite = 31
+)
+
+// MarshalCBOR produces the CBOR representation of a DID document, the values of the document are encoded as
+// they are in its JSON representation, with numbers that have no fractional part encoded as integers.
+// The encoding is deterministic, it follows the core deterministic encoding requirements of RFC 8949:
+// integers, lengths and floats are encoded in their shortest form, and the keys of maps are sorted,
+// so the same document is always encoded into the same bytes, which can be hashed or signed.
+// A value in Properties that is not a generic value is encoded as its JSON representation would be.
+// https://www.rfc-editor.org/rfc/rfc8949#section-4.2.1
+func (doc *Document) MarshalCBOR() ([]byte, error) {
+	m, err := doc.toValue()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := encodeCBORValue(&buf, m, ""); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalCBOR consumes the CBOR representation of a DID document, it is the same as DecodeCBOR with
+// the default options
+func (doc *Document) UnmarshalCBOR(data []byte) error {
+	decoded, err := DecodeCBOR(data, DecodeOptions{})
+	if err != nil {
+		return err
+	}
+	*doc = *decoded
+	return nil
+}
+
+// DecodeCBOR consumes the CBOR representation of a DID document, which is decoded like its JSON representation.
+// Integers are decoded as float64 in Properties, unless they cannot be represented exactly by a float64,
+// and byte strings are decoded as []byte. With the Strict option, the document must also be deterministically
+// encoded, like MarshalCBOR encodes it. The returned error is a *DocumentError with the JSON pointer of the
+// value that is not valid, see DecodeJSON.
+func DecodeCBOR(data []byte, opts DecodeOptions) (*Document, error) {
+	dec := &cborDecoder{data: data, opts: opts}
+
+	v, err := dec.value("", 0)
+	if err != nil {
+		return nil, err
+	}
+	if dec.offset != len(data) {
+		return nil, documentError("", ErrInvalidCBOR, "DID document is followed by more data")
+	}
+
+	if opts.Strict {
+		var buf bytes.Buffer
+		if err := encodeCBORValue(&buf, v, ""); err != nil || !bytes.Equal(buf.Bytes(), data) {
+			return nil, documentError("", ErrInvalidCBOR, "DID document is not deterministically encoded")
+		}
+	}
+
+	dc := &decoder{opts: opts}
+	return dc.document(v)
+}
+
+// encodeCBORValue encodes a value of a DID document at pointer into buf
+func encodeCBORValue(buf *bytes.Buffer, v interface{}, pointer string) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(cborNull) // nolint, returned error is always nil
+	case bool:
+		if v {
+			buf.WriteByte(cborTrue) // nolint, returned error is always nil
+		} else {
+			buf.WriteByte(cborFalse) // nolint, returned error is always nil
+		}
+	case string:
+		writeCBORHead(buf, cborText, uint64(len(v)))
+		buf.WriteString(v) // nolint, returned error is always nil
+	case []byte:
+		writeCBORHead(buf, cborBytes, uint64(len(v)))
+		buf.Write(v) // nolint, returned error is always nil
+	case float64:
+		writeCBORNumber(buf, v)
+	case float32:
+		writeCBORNumber(buf, float64(v))
+	case int:
+		writeCBORInt(buf, int64(v))
+	case int8:
+		writeCBORInt(buf, int64(v))
+	case int16:
+		writeCBORInt(buf, int64(v))
+	case int32:
+		writeCBORInt(buf, int64(v))
+	case int64:
+		writeCBORInt(buf, v)
+	case uint:
+		writeCBORHead(buf, cborUnsigned, uint64(v))
+	case uint8:
+		writeCBORHead(buf, cborUnsigned, uint64(v))
+	case uint16:
+		writeCBORHead(buf, cborUnsigned, uint64(v))
+	case uint32:
+		writeCBORHead(buf, cborUnsigned, uint64(v))
+	case uint64:
+		writeCBORHead(buf, cborUnsigned, v)
+	case []interface{}:
+		writeCBORHead(buf, cborArray, uint64(len(v)))
+		for i, e := range v {
+			if err := encodeCBORValue(buf, e, appendPointer(pointer, i)); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		// the keys are sorted in the bytewise lexicographic order of their encodings
+		type entry struct {
+			key   []byte
+			name  string
+			value interface{}
+		}
+		entries := make([]entry, 0, len(v))
+		for name, value := range v {
+			var key bytes.Buffer
+			writeCBORHead(&key, cborText, uint64(len(name)))
+			key.WriteString(name) // nolint, returned error is always nil
+			entries = append(entries, entry{key: key.Bytes(), name: name, value: value})
+		}
+		sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i].key, entries[j].key) < 0 })
+
+		writeCBORHead(buf, cborMap, uint64(len(v)))
+		for _, e := range entries {
+			buf.Write(e.key) // nolint, returned error is always nil
+			if err := encodeCBORValue(buf, e.value, appendPointer(pointer, e.name)); err != nil {
+				return err
+			}
+		}
+	default:
+		// any other value is encoded as its JSON representation would be
+		generic, err := toGenericValue(v)
+		if err != nil {
+			return documentError(pointer, ErrInvalidType, "value of type %T cannot be encoded: %v", v, err)
+		}
+		return encodeCBORValue(buf, generic, pointer)
+	}
+
+	return nil
+}
+
+// toGenericValue returns the generic value of the JSON representation of v
+func toGenericValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	err = json.Unmarshal(data, &generic)
+	return generic, err
+}
+
+// writeCBORHead writes the head of a data item of a major type, with its argument in its shortest form
+// https://www.rfc-editor.org/rfc/rfc8949#section-3
+func writeCBORHead(buf *bytes.Buffer, major byte, argument uint64) {
+	switch {
+	case argument < 24:
+		buf.WriteByte(major | byte(argument)) // nolint, returned error is always nil
+	case argument <= math.MaxUint8:
+		buf.WriteByte(major | 24)     // nolint, returned error is always nil
+		buf.WriteByte(byte(argument)) // nolint, returned error is always nil
+	case argument <= math.MaxUint16:
+		buf.WriteByte(major | 25)                                       // nolint, returned error is always nil
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(argument))) // nolint, returned error is always nil
+	case argument <= math.MaxUint32:
+		buf.WriteByte(major | 26)                                       // nolint, returned error is always nil
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(argument))) // nolint, returned error is always nil
+	default:
+		buf.WriteByte(major | 27)                               // nolint, returned error is always nil
+		buf.Write(binary.BigEndian.AppendUint64(nil, argument)) // nolint, returned error is always nil
+	}
+}
+
+// writeCBORInt writes an integer as an unsigned or a negative integer
+func writeCBORInt(buf *bytes.Buffer, n int64) {
+	if n < 0 {
+		// a negative integer n is encoded as -1-n
+		writeCBORHead(buf, cborNegative, uint64(-1-n))
+		return
+	}
+	writeCBORHead(buf, cborUnsigned, uint64(n))
+}
+
+// writeCBORNumber writes a number as an integer if it has no fractional part and fits in 64 bits,
+// and otherwise as a float in the shortest form that preserves its value
+// https://www.rfc-editor.org/rfc/rfc8949#section-4.2.2
+func writeCBORNumber(buf *bytes.Buffer, f float64) {
+	switch {
+	case f == math.Trunc(f) && f >= -(1<<63) && f < 1<<63 && !(f == 0 && math.Signbit(f)):
+		writeCBORInt(buf, int64(f))
+		return
+	case f == math.Trunc(f) && f >= 1<<63 && f < 1<<64:
+		writeCBORHead(buf, cborUnsigned, uint64(f))
+		return
+	}
+
+	if half, ok := float16Bits(f); ok {
+		buf.WriteByte(cborFloat16)                          // nolint, returned error is always nil
+		buf.Write(binary.BigEndian.AppendUint16(nil, half)) // nolint, returned error is always nil
+	} else if f32 := float32(f); float64(f32) == f {
+		buf.WriteByte(cborFloat32)                                           // nolint, returned error is always nil
+		buf.Write(binary.BigEndian.AppendUint32(nil, math.Float32bits(f32))) // nolint, returned error is always nil
+	} else {
+		buf.WriteByte(cborFloat64)                                         // nolint, returned error is always nil
+		buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(f))) // nolint, returned error is always nil
+	}
+}
+
+// float16Bits returns the bits of the IEEE 754 half-precision float that is equal to f, ok is false
+// if there is none. Every NaN is encoded as the same quiet NaN.
+func float16Bits(f float64) (bits uint16, ok bool) {
+	var sign uint16
+	if math.Signbit(f) {
+		sign = 0x8000
+	}
+
+	switch {
+	case math.IsNaN(f):
+		return 0x7e00, true
+	case math.IsInf(f, 0):
+		return sign | 0x7c00, true
+	case f == 0:
+		return sign, true
+	}
+
+	f32 := float32(f)
+	if float64(f32) != f {
+		return 0, false
+	}
+
+	b := math.Float32bits(f32)
+	exp := int((b>>23)&0xff) - 127
+	mantissa := b & 0x7fffff
+
+	switch {
+	case exp >= -14 && exp <= 15:
+		// a normal half-precision float has a 10 bit mantissa
+		if mantissa&0x1fff != 0 {
+			return 0, false
+		}
+		return sign | uint16(exp+15)<<10 | uint16(mantissa>>13), true
+	case exp >= -24 && exp < -14:
+		// a subnormal half-precision float is a multiple of 2^-24
+		full := mantissa | 0x800000
+		shift := uint(-1 - exp)
+		if full&(1<<shift-1) != 0 {
+			return 0, false
+		}
+		return sign | uint16(full>>shift), true
+	default:
+		return 0, false
+	}
+}
+
+// float16Value returns the value of the bits of an IEEE 754 half-precision float
+func float16Value(bits uint16) float64 {
+	exp := int(bits>>10) & 0x1f
+	mantissa := float64(bits & 0x3ff)
+
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mantissa, -24)
+	case 0x1f:
+		if mantissa != 0 {
+			f = math.NaN()
+		} else {
+			f = math.Inf(1)
+		}
+	default:
+		f = math.Ldexp(mantissa+1024, exp-25)
+	}
+
+	if bits&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
+
+// cborDecoder decodes the CBOR representation of a DID document into a generic value
+type cborDecoder struct {
+	data   []byte
+	offset int
+	opts   DecodeOptions
+}
+
+// value decodes the data item at the offset of the decoder, which is at pointer in the document
+// and is nested in depth arrays and maps
+func (dec *cborDecoder) value(pointer string, depth int) (interface{}, error) {
+	if depth > maxCBORDepth {
+		return nil, documentError(pointer, ErrInvalidCBOR, "arrays and maps are nested too deep")
+	}
+
+	initial, argument, err := dec.head(pointer)
+	if err != nil {
+		return nil, err
+	}
+	major, info := initial&0xe0, initial&0x1f
+
+	switch major {
+	case cborUnsigned:
+		if argument <= 1<<53 {
+			return float64(argument), nil
+		}
+		if argument <= math.MaxInt64 {
+			return int64(argument), nil
+		}
+		return argument, nil
+
+	case cborNegative:
+		// the value is -1-argument
+		if argument < 1<<53 {
+			return -1 - float64(argument), nil
+		}
+		if argument <= math.MaxInt64 {
+			return -1 - int64(argument), nil
+		}
+		return nil, documentError(pointer, ErrInvalidCBOR, "negative integer does not fit in 64 bits")
+
+	case cborBytes, cborText:
+		s, err := dec.chunks(major, info, argument, pointer)
+		if err != nil {
+			return nil, err
+		}
+		if major == cborBytes {
+			return s, nil
+		}
+		if !utf8.Valid(s) {
+			return nil, documentError(pointer, ErrInvalidCBOR, "text string is not valid UTF-8")
+		}
+		return string(s), nil
+
+	case cborArray:
+		values := []interface{}{}
+		for i := 0; info == cborIndefinite || uint64(i) < argument; i++ {
+			if info == cborIndefinite && dec.isBreak() {
+				break
+			}
+			value, err := dec.value(appendPointer(pointer, i), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+
+	case cborMap:
+		m := make(map[string]interface{})
+		for i := uint64(0); info == cborIndefinite || i < argument; i++ {
+			if info == cborIndefinite && dec.isBreak() {
+				break
+			}
+			key, err := dec.value(pointer, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			name, ok := key.(string)
+			if !ok {
+				return nil, documentError(pointer, ErrInvalidType, "key of a map must be a text string, not %s",
+					typeName(key))
+			}
+			keyPointer := appendPointer(pointer, name)
+
+			if _, ok := m[name]; ok && dec.opts.Strict {
+				return nil, documentError(keyPointer, ErrDuplicateKey, "key %q appears more than once", name)
+			}
+
+			if m[name], err = dec.value(keyPointer, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+
+	case cborTag:
+		return nil, documentError(pointer, ErrInvalidCBOR, "tag %d is not supported", argument)
+
+	default:
+		switch initial {
+		case cborFalse:
+			return false, nil
+		case cborTrue:
+			return true, nil
+		case cborNull:
+			return nil, nil
+		case cborFloat16:
+			return float16Value(uint16(argument)), nil
+		case cborFloat32:
+			return float64(math.Float32frombits(uint32(argument))), nil
+		case cborFloat64:
+			return math.Float64frombits(argument), nil
+		}
+		return nil, documentError(pointer, ErrInvalidCBOR, "simple value %d is not supported", info)
+	}
+}
+
+// head decodes the head of the data item at the offset of the decoder, and returns its initial byte and argument
+// https://www.rfc-editor.org/rfc/rfc8949#section-3
+func (dec *cborDecoder) head(pointer string) (byte, uint64, error) {
+	if dec.offset >= len(dec.data) {
+		return 0, 0, documentError(pointer, ErrInvalidCBOR, "unexpected end of data")
+	}
+	initial := dec.data[dec.offset]
+	dec.offset = dec.offset + 1
+
+	info := initial & 0x1f
+	switch {
+	case info < 24:
+		return initial, uint64(info), nil
+	case info == cborIndefinite:
+		major := initial & 0xe0
+		if major == cborBytes || major == cborText || major == cborArray || major == cborMap {
+			return initial, 0, nil
+		}
+		// a break outside of an indefinite length data item, or an indefinite length integer or tag
+		return 0, 0, documentError(pointer, ErrInvalidCBOR, "unexpected indefinite length or break")
+	case info > 27:
+		return 0, 0, documentError(pointer, ErrInvalidCBOR, "reserved additional information %d", info)
+	}
+
+	size := 1 << (info - 24)
+	if len(dec.data)-dec.offset < size {
+		return 0, 0, documentError(pointer, ErrInvalidCBOR, "unexpected end of data")
+	}
+	var argument uint64
+	for _, b := range dec.data[dec.offset : dec.offset+size] {
+		argument = argument<<8 | uint64(b)
+	}
+	dec.offset = dec.offset + size
+
+	return initial, argument, nil
+}
+
+// chunks decodes the content of a byte or text string, which is the concatenation of its chunks
+// if its length is indefinite
+func (dec *cborDecoder) chunks(major, info byte, length uint64, pointer string) ([]byte, error) {
+	if info != cborIndefinite {
+		if length > uint64(len(dec.data)-dec.offset) {
+			return nil, documentError(pointer, ErrInvalidCBOR, "unexpected end of data")
+		}
+		s := dec.data[dec.offset : dec.offset+int(length)]
+		dec.offset = dec.offset + int(length)
+		return append([]byte{}, s...), nil
+	}
+
+	s := []byte{}
+	for !dec.isBreak() {
+		initial, length, err := dec.head(pointer)
+		if err != nil {
+			return nil, err
+		}
+		// the chunks must be definite length strings of the same major type
+		if initial&0xe0 != major || initial&0x1f == cborIndefinite {
+			return nil, documentError(pointer, ErrInvalidCBOR, "chunk of an indefinite length string is not valid")
+		}
+		chunk, err := dec.chunks(major, initial&0x1f, length, pointer)
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, chunk...)
+	}
+	return s, nil
+}
+
+// isBreak returns true, and skips it, if the data item at the offset of the decoder is a break,
+// which ends an indefinite length data item
+func (dec *cborDecoder) isBreak() bool {
+	if dec.offset < len(dec.data) && dec.data[dec.offset] == cborBreak {
+		dec.offset = dec.offset + 1
+		return true
+	}
+	return false
+}
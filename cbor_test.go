@@ -0,0 +1,242 @@
+package did
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestEncodeCBORValue(t *testing.T) {
+	// the examples of RFC 8949 appendix A that are in the deterministic encoding
+	tests := []struct {
+		value   interface{}
+		encoded string
+	}{
+		{float64(0), "00"},
+		{float64(1), "01"},
+		{float64(10), "0a"},
+		{float64(23), "17"},
+		{float64(24), "1818"},
+		{float64(25), "1819"},
+		{float64(100), "1864"},
+		{float64(1000), "1903e8"},
+		{float64(1000000), "1a000f4240"},
+		{float64(1000000000000), "1b000000e8d4a51000"},
+		{uint64(18446744073709551615), "1bffffffffffffffff"},
+		{float64(18446744073709549568), "1bfffffffffffff800"},
+		{float64(-1), "20"},
+		{float64(-10), "29"},
+		{float64(-100), "3863"},
+		{float64(-1000), "3903e7"},
+		{int64(math.MinInt64), "3b7fffffffffffffff"},
+		{math.Copysign(0, -1), "f98000"},
+		{1.1, "fb3ff199999999999a"},
+		{1.5, "f93e00"},
+		{float64(65504.5), "fa477fe080"},
+		{100000.5, "fa47c35040"},
+		{3.4028234663852886e+38 + 0.5, "fa7f7fffff"},
+		{1.0e+300, "fb7e37e43c8800759c"},
+		{5.960464477539063e-8, "f90001"},
+		{0.00006103515625, "f90400"},
+		{-4.1, "fbc010666666666666"},
+		{math.Inf(1), "f97c00"},
+		{math.NaN(), "f97e00"},
+		{math.Inf(-1), "f9fc00"},
+		{false, "f4"},
+		{true, "f5"},
+		{nil, "f6"},
+		{"", "60"},
+		{"a", "6161"},
+		{"IETF", "6449455446"},
+		{"\"\\", "62225c"},
+		{"ü", "62c3bc"},
+		{"水", "63e6b0b4"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{[]interface{}{}, "80"},
+		{[]interface{}{float64(1), float64(2), float64(3)}, "83010203"},
+		{[]interface{}{float64(1), []interface{}{float64(2), float64(3)}, []interface{}{float64(4), float64(5)}},
+			"8301820203820405"},
+		{map[string]interface{}{}, "a0"},
+		{map[string]interface{}{"a": float64(1), "b": []interface{}{float64(2), float64(3)}}, "a26161016162820203"},
+		{map[string]interface{}{"a": "A", "b": "B", "c": "C", "d": "D", "e": "E"},
+			"a56161614161626142616361436164614461656145"},
+		// keys are sorted by length first
+		{map[string]interface{}{"bb": float64(1), "a": float64(2), "c": float64(3)}, "a3616102616303626262" + "01"},
+		{[]string{"a"}, "816161"},
+		{map[string]int{"a": 1}, "a1616101"},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		err := encodeCBORValue(&buf, test.value, "")
+		assert(t, nil, err, "Value: %#v", test.value)
+		assert(t, test.encoded, hex.EncodeToString(buf.Bytes()), "Value: %#v", test.value)
+
+		dec := &cborDecoder{data: buf.Bytes()}
+		decoded, err := dec.value("", 0)
+		assert(t, nil, err, "Value: %#v", test.value)
+
+		// a decoded value encodes into the same bytes
+		var again bytes.Buffer
+		assert(t, nil, encodeCBORValue(&again, decoded, ""), "Value: %#v", test.value)
+		assert(t, test.encoded, hex.EncodeToString(again.Bytes()), "Value: %#v", test.value)
+	}
+
+	var buf bytes.Buffer
+	err := encodeCBORValue(&buf, map[string]interface{}{"a": []interface{}{make(chan int)}}, "/x")
+	var de *DocumentError
+	assert(t, true, errors.As(err, &de))
+	assert(t, "/x/a/0", de.Pointer)
+}
+
+func TestCBORDecoder(t *testing.T) {
+	tests := []struct {
+		encoded string
+		value   interface{}
+	}{
+		// the examples of RFC 8949 appendix A that are not in the deterministic encoding
+		{"f90000", float64(0)},
+		{"f93c00", float64(1)},
+		{"fa47c35000", float64(100000)},
+		{"f97bff", float64(65504)},
+		{"fb7ff0000000000000", math.Inf(1)},
+		{"5f42010243030405ff", []byte{1, 2, 3, 4, 5}},
+		{"7f657374726561646d696e67ff", "streaming"},
+		{"9fff", []interface{}{}},
+		{"9f018202039f0405ffff", []interface{}{float64(1), []interface{}{float64(2), float64(3)},
+			[]interface{}{float64(4), float64(5)}}},
+		{"bf61610161629f0203ffff", map[string]interface{}{"a": float64(1), "b": []interface{}{float64(2), float64(3)}}},
+		{"1818", float64(24)},
+		{"190018", float64(24)},
+		{"1b0020000000000001", int64(1<<53 + 1)},
+		{"3b0020000000000000", int64(-(1<<53 + 1))},
+		{"1bffffffffffffffff", uint64(math.MaxUint64)},
+	}
+
+	for _, test := range tests {
+		data, err := hex.DecodeString(test.encoded)
+		assert(t, nil, err)
+		dec := &cborDecoder{data: data}
+		value, err := dec.value("", 0)
+		assert(t, nil, err, "Encoded: %s", test.encoded)
+		assert(t, test.value, value, "Encoded: %s", test.encoded)
+		assert(t, len(data), dec.offset, "Encoded: %s", test.encoded)
+	}
+}
+
+func TestCBORRoundTrip(t *testing.T) {
+	for _, name := range []string{"example.json", "example-ld.json"} {
+		doc, err := DecodeJSON(readDocument(t, name), DecodeOptions{Strict: true})
+		assert(t, nil, err, "Document: %s", name)
+
+		encoded, err := doc.MarshalCBOR()
+		assert(t, nil, err, "Document: %s", name)
+
+		decoded, err := DecodeCBOR(encoded, DecodeOptions{Strict: true})
+		assert(t, nil, err, "Document: %s", name)
+		assert(t, doc, decoded, "Document: %s", name)
+
+		var fromCBOR Document
+		assert(t, nil, fromCBOR.UnmarshalCBOR(encoded))
+
+		// the JSON representation of the decoded document is the same as the JSON representation of the document
+		expected, err := doc.MarshalJSON()
+		assert(t, nil, err)
+		actual, err := fromCBOR.MarshalJSON()
+		assert(t, nil, err)
+		assert(t, string(expected), string(actual), "Document: %s", name)
+
+		// the encoding is deterministic
+		again, err := decoded.MarshalCBOR()
+		assert(t, nil, err)
+		assert(t, encoded, again, "Document: %s", name)
+	}
+}
+
+func TestMarshalCBOR(t *testing.T) {
+	doc := &Document{
+		Context: []interface{}{ContextV1},
+		ID:      mustParse(t, "did:example:123"),
+		Properties: map[string]interface{}{
+			"n":      float64(2),
+			"struct": struct{ A []int }{A: []int{1}},
+		},
+	}
+
+	encoded, err := doc.MarshalCBOR()
+	assert(t, nil, err)
+
+	var expected bytes.Buffer
+	err = encodeCBORValue(&expected, map[string]interface{}{
+		"@context": ContextV1,
+		"id":       "did:example:123",
+		"n":        float64(2),
+		"struct":   map[string]interface{}{"A": []interface{}{float64(1)}},
+	}, "")
+	assert(t, nil, err)
+	assert(t, hex.EncodeToString(expected.Bytes()), hex.EncodeToString(encoded))
+
+	// the keys are sorted by the length of their encoding first
+	assert(t, "a4616e02626964", hex.EncodeToString(encoded[:7]))
+
+	data, err := json.Marshal(doc)
+	assert(t, nil, err)
+	assert(t, `{"@context":"https://www.w3.org/ns/did/v1","id":"did:example:123","n":2,"struct":{"A":[1]}}`, string(data))
+}
+
+func TestDecodeCBORErrors(t *testing.T) {
+	tests := []struct {
+		encoded string
+		strict  bool
+		pointer string
+		err     error
+	}{
+		{"", false, "", ErrInvalidCBOR},
+		{"80", false, "", ErrInvalidType},
+		{"a0a0", false, "", ErrInvalidCBOR},
+		{"a1", false, "", ErrInvalidCBOR},
+		{"a16269641a", false, "/id", ErrInvalidCBOR},
+		{"a1626964c074", false, "/id", ErrInvalidCBOR},
+		{"a1626964f0", false, "/id", ErrInvalidCBOR},
+		{"a1626964ff", false, "/id", ErrInvalidCBOR},
+		{"a162696462ff00", false, "/id", ErrInvalidCBOR},
+		{"a10101", false, "", ErrInvalidType},
+		{"a1626964187b", false, "/id", ErrInvalidType},
+		{"a16269646b6469643a6578613a315e33", false, "/id", ErrInvalidCharacter},
+		{"a16269647f4164ff", false, "/id", ErrInvalidCBOR},
+		{"a1626964617800", false, "", ErrInvalidCBOR},
+		{"a2617801617802", true, "/x", ErrDuplicateKey},
+		{"a161781817", true, "", ErrInvalidCBOR},
+		{"a16178190018", true, "", ErrInvalidCBOR},
+		{"bf617801ff", true, "", ErrInvalidCBOR},
+		{"a2617901617801", true, "", ErrInvalidCBOR},
+		{"a16178f93c00", true, "", ErrInvalidCBOR},
+	}
+
+	for _, test := range tests {
+		data, err := hex.DecodeString(test.encoded)
+		assert(t, nil, err)
+		_, err = DecodeCBOR(data, DecodeOptions{Strict: test.strict})
+
+		var de *DocumentError
+		assert(t, true, errors.As(err, &de), "Encoded: %s Error: %v", test.encoded, err)
+		assert(t, test.pointer, de.Pointer, "Encoded: %s Error: %v", test.encoded, err)
+		assert(t, true, errors.Is(err, test.err), "Encoded: %s Error: %v", test.encoded, err)
+	}
+
+	// the same documents are accepted when they are not strictly decoded
+	for _, encoded := range []string{"a2617801617802", "a161781817", "a16178190018", "bf617801ff", "a2617901617801", "a16178f93c00"} {
+		data, err := hex.DecodeString(encoded)
+		assert(t, nil, err)
+		_, err = DecodeCBOR(data, DecodeOptions{})
+		assert(t, nil, err, "Encoded: %s", encoded)
+	}
+
+	// deeply nested arrays are rejected
+	data := append(bytes.Repeat([]byte{0x81}, maxCBORDepth+2), 0x01)
+	_, err := DecodeCBOR(append([]byte{0xa1, 0x61, 0x78}, data...), DecodeOptions{})
+	assert(t, true, errors.Is(err, ErrInvalidCBOR))
+}
@@ -0,0 +1,109 @@
+package did
+
+import (
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/ockam-network/did/internal/abnf"
+)
+
+// loadGrammar parses did.abnf
+func loadGrammar(t testing.TB) *abnf.Grammar {
+	src, err := os.ReadFile("did.abnf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grammar, err := abnf.Parse(string(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return grammar
+}
+
+// checkConformance fails the test if Parse does not accept input exactly when it matches the did-url rule
+// of the grammar, or if the String of an accepted input does not round trip
+func checkConformance(t *testing.T, grammar *abnf.Grammar, input string) {
+	matches, err := grammar.Match("did-url", input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, spans, err := ParseWithSpans(input, ParseOptions{})
+	if matches != (err == nil) {
+		t.Fatalf("did.abnf matches %q: %v, Parse returns error: %v", input, matches, err)
+	}
+	if err != nil {
+		return
+	}
+
+	s := d.String()
+	if matches, _ := grammar.Match("did-url", s); !matches {
+		t.Fatalf("String of %q is %q, which does not match did.abnf", input, s)
+	}
+
+	// String omits a `?` or a `#` that is followed by an empty query or fragment
+	emptyQuery := spans.Query != (Span{}) && spans.Query.Start == spans.Query.End
+	emptyFragment := spans.Fragment != (Span{}) && spans.Fragment.Start == spans.Fragment.End
+	if s != input && !emptyQuery && !emptyFragment {
+		t.Fatalf("String of %q is %q", input, s)
+	}
+
+	roundTrip, err := Parse(s)
+	if err != nil {
+		t.Fatalf("String of %q is %q, which does not parse: %v", input, s, err)
+	}
+	assert(t, d, roundTrip, "Input: %s", input)
+}
+
+func TestConformance(t *testing.T) {
+	grammar := loadGrammar(t)
+	r := rand.New(rand.NewSource(1))
+
+	inputs := []string{
+		"did:a:1",
+		"DID:a:1",
+		"did:a:_",
+		"did:a:%2f",
+		"did:a::1",
+		"did:a:1:",
+		"did:a:1;p",
+		"did:a:1/",
+		"did:a:1//",
+		"did:a:1?#",
+		"did:a:1#a#",
+	}
+
+	for i := 0; i < 2000; i++ {
+		valid, err := grammar.Generate("did-url", r)
+		assert(t, nil, err)
+		inputs = append(inputs, valid, abnf.Mutate(valid, r), abnf.Mutate(abnf.Mutate(valid, r), r))
+	}
+
+	for _, input := range inputs {
+		checkConformance(t, grammar, input)
+	}
+}
+
+func FuzzConformance(f *testing.F) {
+	grammar := loadGrammar(f)
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		valid, err := grammar.Generate("did-url", r)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(valid)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		// the matcher is quadratic in the length of the input
+		if len(input) > 256 {
+			t.Skip()
+		}
+		checkConformance(t, grammar, input)
+	})
+}
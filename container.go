@@ -0,0 +1,386 @@
+package did
+
+import (
+	"iter"
+	"sort"
+	"sync"
+)
+
+// A Map maps DIDs and DID URLs to values of type V. Keys are compared in their normal form, see Normalize,
+// and are indexed by a trie over their Method and IDStrings, so all the DIDs of a method, of an idstring prefix
+// or of a base DID can be found without scanning the whole map. Iterators yield entries in Compare order.
+// The keys yielded by iterators are the normalized copies held by the map, they must not be modified,
+// and the map must not be modified during an iteration.
+// The zero value of a Map is an empty map ready to use. A Map is not safe for concurrent use, see SyncMap.
+type Map[V any] struct {
+	root trieNode[V]
+	len  int
+}
+
+// a trieNode is the node of a method, if it is a child of the root, or of an idstring
+type trieNode[V any] struct {
+	children map[string]*trieNode[V]
+
+	// the entries whose method and idstrings lead to this node, sorted in Compare order
+	entries []mapEntry[V]
+}
+
+type mapEntry[V any] struct {
+	key   *DID
+	value V
+}
+
+// Get returns the value of a DID, and whether it is in the map
+func (m *Map[V]) Get(d *DID) (V, bool) {
+	key := d.Normalize()
+
+	if node := m.root.find(triePath(key), false); node != nil {
+		if i, found := node.search(key); found {
+			return node.entries[i].value, true
+		}
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Put sets the value of a DID, replacing its previous value
+func (m *Map[V]) Put(d *DID, value V) {
+	key := d.Normalize()
+	node := m.root.find(triePath(key), true)
+
+	i, found := node.search(key)
+	if found {
+		node.entries[i].value = value
+		return
+	}
+
+	node.entries = append(node.entries, mapEntry[V]{})
+	copy(node.entries[i+1:], node.entries[i:])
+	node.entries[i] = mapEntry[V]{key: key, value: value}
+	m.len = m.len + 1
+}
+
+// Delete removes a DID from the map, it returns true if the DID was in the map
+func (m *Map[V]) Delete(d *DID) bool {
+	key := d.Normalize()
+	path := triePath(key)
+
+	// the nodes along the path, to remove the nodes that are left empty
+	nodes := []*trieNode[V]{&m.root}
+	for _, name := range path {
+		child := nodes[len(nodes)-1].children[name]
+		if child == nil {
+			return false
+		}
+		nodes = append(nodes, child)
+	}
+
+	node := nodes[len(nodes)-1]
+	i, found := node.search(key)
+	if !found {
+		return false
+	}
+	node.entries = append(node.entries[:i], node.entries[i+1:]...)
+	m.len = m.len - 1
+
+	for j := len(nodes) - 1; j > 0 && len(nodes[j].entries) == 0 && len(nodes[j].children) == 0; j-- {
+		delete(nodes[j-1].children, path[j-1])
+	}
+
+	return true
+}
+
+// Len returns the number of DIDs in the map
+func (m *Map[V]) Len() int {
+	return m.len
+}
+
+// All returns an iterator over all the entries of the map
+func (m *Map[V]) All() iter.Seq2[*DID, V] {
+	return func(yield func(*DID, V) bool) {
+		m.root.walk(yield)
+	}
+}
+
+// Method returns an iterator over the entries whose DID has the given method
+func (m *Map[V]) Method(method string) iter.Seq2[*DID, V] {
+	return m.under([]string{method})
+}
+
+// Prefix returns an iterator over the entries whose DID has the method of prefix, and IDStrings that begin with
+// the IDStrings of prefix, ex- the prefix did:example:org:acme yields did:example:org:acme and
+// did:example:org:acme:alice#key-1 but not did:example:org:acmes
+func (m *Map[V]) Prefix(prefix *DID) iter.Seq2[*DID, V] {
+	return m.under(triePath(prefix.Normalize()))
+}
+
+// Base returns an iterator over the entries whose DID has the same method and method-specific-id as d,
+// ex- did:example:123 yields did:example:123, did:example:123/path and did:example:123#key-1
+func (m *Map[V]) Base(d *DID) iter.Seq2[*DID, V] {
+	path := triePath(d.Normalize())
+	return func(yield func(*DID, V) bool) {
+		if node := m.root.find(path, false); node != nil {
+			for _, entry := range node.entries {
+				if !yield(entry.key, entry.value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// under returns an iterator over the entries of the node at path and of its descendants
+func (m *Map[V]) under(path []string) iter.Seq2[*DID, V] {
+	return func(yield func(*DID, V) bool) {
+		if node := m.root.find(path, false); node != nil {
+			node.walk(yield)
+		}
+	}
+}
+
+// triePath returns the path of a normalized DID in the trie, which is its method followed by its idstrings
+func triePath(key *DID) []string {
+	return append([]string{key.Method}, key.IDStrings...)
+}
+
+// find returns the node at path, the nodes along the path are created if create is true, otherwise find
+// returns nil if there is no node at path
+func (n *trieNode[V]) find(path []string, create bool) *trieNode[V] {
+	node := n
+	for _, name := range path {
+		child := node.children[name]
+		if child == nil {
+			if !create {
+				return nil
+			}
+			if node.children == nil {
+				node.children = make(map[string]*trieNode[V])
+			}
+			child = &trieNode[V]{}
+			node.children[name] = child
+		}
+		node = child
+	}
+	return node
+}
+
+// search returns the index of the entry of a normalized DID, or the index at which it would be inserted
+func (n *trieNode[V]) search(key *DID) (int, bool) {
+	i := sort.Search(len(n.entries), func(i int) bool {
+		return compareNormal(n.entries[i].key, key) >= 0
+	})
+	return i, i < len(n.entries) && compareNormal(n.entries[i].key, key) == 0
+}
+
+// walk yields the entries of a node and of its descendants in Compare order, it returns false if yield does.
+// The entries of a node have fewer idstrings than those of its children, so they sort first.
+func (n *trieNode[V]) walk(yield func(*DID, V) bool) bool {
+	for _, entry := range n.entries {
+		if !yield(entry.key, entry.value) {
+			return false
+		}
+	}
+
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !n.children[name].walk(yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// A Set is a set of DIDs and DID URLs, it is a Map without values.
+// The zero value of a Set is an empty set ready to use. A Set is not safe for concurrent use, see SyncSet.
+type Set struct {
+	m Map[struct{}]
+}
+
+// Add adds a DID to the set, it returns true if the DID was not already in the set
+func (s *Set) Add(d *DID) bool {
+	if s.Contains(d) {
+		return false
+	}
+	s.m.Put(d, struct{}{})
+	return true
+}
+
+// Contains returns true if a DID is in the set
+func (s *Set) Contains(d *DID) bool {
+	_, ok := s.m.Get(d)
+	return ok
+}
+
+// Remove removes a DID from the set, it returns true if the DID was in the set
+func (s *Set) Remove(d *DID) bool {
+	return s.m.Delete(d)
+}
+
+// Len returns the number of DIDs in the set
+func (s *Set) Len() int {
+	return s.m.Len()
+}
+
+// All returns an iterator over all the DIDs of the set
+func (s *Set) All() iter.Seq[*DID] {
+	return keys(s.m.All())
+}
+
+// Method returns an iterator over the DIDs of the set that have the given method
+func (s *Set) Method(method string) iter.Seq[*DID] {
+	return keys(s.m.Method(method))
+}
+
+// Prefix returns an iterator over the DIDs of the set that begin with prefix, see Map.Prefix
+func (s *Set) Prefix(prefix *DID) iter.Seq[*DID] {
+	return keys(s.m.Prefix(prefix))
+}
+
+// Base returns an iterator over the DIDs of the set that have the same base DID as d, see Map.Base
+func (s *Set) Base(d *DID) iter.Seq[*DID] {
+	return keys(s.m.Base(d))
+}
+
+// keys returns an iterator over the keys of seq
+func keys[V any](seq iter.Seq2[*DID, V]) iter.Seq[*DID] {
+	return func(yield func(*DID) bool) {
+		for key := range seq {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+// A SyncMap is a Map that is safe for concurrent use. Its iterators yield a snapshot of the entries
+// that were in the map when the iteration began, the map may be modified during an iteration.
+type SyncMap[V any] struct {
+	mu sync.RWMutex
+	m  Map[V]
+}
+
+// Get returns the value of a DID, and whether it is in the map
+func (m *SyncMap[V]) Get(d *DID) (V, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.m.Get(d)
+}
+
+// Put sets the value of a DID, replacing its previous value
+func (m *SyncMap[V]) Put(d *DID, value V) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.m.Put(d, value)
+}
+
+// Delete removes a DID from the map, it returns true if the DID was in the map
+func (m *SyncMap[V]) Delete(d *DID) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.m.Delete(d)
+}
+
+// Len returns the number of DIDs in the map
+func (m *SyncMap[V]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.m.Len()
+}
+
+// All returns an iterator over all the entries of the map
+func (m *SyncMap[V]) All() iter.Seq2[*DID, V] {
+	return m.snapshot(func() iter.Seq2[*DID, V] { return m.m.All() })
+}
+
+// Method returns an iterator over the entries whose DID has the given method
+func (m *SyncMap[V]) Method(method string) iter.Seq2[*DID, V] {
+	return m.snapshot(func() iter.Seq2[*DID, V] { return m.m.Method(method) })
+}
+
+// Prefix returns an iterator over the entries whose DID begins with prefix, see Map.Prefix
+func (m *SyncMap[V]) Prefix(prefix *DID) iter.Seq2[*DID, V] {
+	return m.snapshot(func() iter.Seq2[*DID, V] { return m.m.Prefix(prefix) })
+}
+
+// Base returns an iterator over the entries whose DID has the same base DID as d, see Map.Base
+func (m *SyncMap[V]) Base(d *DID) iter.Seq2[*DID, V] {
+	return m.snapshot(func() iter.Seq2[*DID, V] { return m.m.Base(d) })
+}
+
+// snapshot returns an iterator that copies the entries of seq while the map is locked, and then yields them
+func (m *SyncMap[V]) snapshot(seq func() iter.Seq2[*DID, V]) iter.Seq2[*DID, V] {
+	return func(yield func(*DID, V) bool) {
+		m.mu.RLock()
+		var entries []mapEntry[V]
+		for key, value := range seq() {
+			entries = append(entries, mapEntry[V]{key: key, value: value})
+		}
+		m.mu.RUnlock()
+
+		for _, entry := range entries {
+			if !yield(entry.key, entry.value) {
+				return
+			}
+		}
+	}
+}
+
+// A SyncSet is a Set that is safe for concurrent use. Its iterators yield a snapshot of the DIDs
+// that were in the set when the iteration began, the set may be modified during an iteration.
+type SyncSet struct {
+	m SyncMap[struct{}]
+}
+
+// Add adds a DID to the set, it returns true if the DID was not already in the set
+func (s *SyncSet) Add(d *DID) bool {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	if _, ok := s.m.m.Get(d); ok {
+		return false
+	}
+	s.m.m.Put(d, struct{}{})
+	return true
+}
+
+// Contains returns true if a DID is in the set
+func (s *SyncSet) Contains(d *DID) bool {
+	_, ok := s.m.Get(d)
+	return ok
+}
+
+// Remove removes a DID from the set, it returns true if the DID was in the set
+func (s *SyncSet) Remove(d *DID) bool {
+	return s.m.Delete(d)
+}
+
+// Len returns the number of DIDs in the set
+func (s *SyncSet) Len() int {
+	return s.m.Len()
+}
+
+// All returns an iterator over all the DIDs of the set
+func (s *SyncSet) All() iter.Seq[*DID] {
+	return keys(s.m.All())
+}
+
+// Method returns an iterator over the DIDs of the set that have the given method
+func (s *SyncSet) Method(method string) iter.Seq[*DID] {
+	return keys(s.m.Method(method))
+}
+
+// Prefix returns an iterator over the DIDs of the set that begin with prefix, see Map.Prefix
+func (s *SyncSet) Prefix(prefix *DID) iter.Seq[*DID] {
+	return keys(s.m.Prefix(prefix))
+}
+
+// Base returns an iterator over the DIDs of the set that have the same base DID as d, see Map.Base
+func (s *SyncSet) Base(d *DID) iter.Seq[*DID] {
+	return keys(s.m.Base(d))
+}
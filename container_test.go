@@ -0,0 +1,251 @@
+package did
+
+import (
+	"sort"
+	"sync"
+	"testing"
+)
+
+// mustParse parses a DID URL for the container tests
+func mustParse(t *testing.T, input string) *DID {
+	t.Helper()
+	d, err := Parse(input)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", input, err)
+	}
+	return d
+}
+
+// didStrings returns the DIDs of an iterator as strings, and checks that the iterator stops when yield returns false
+func didStrings(t *testing.T, seq func(func(*DID) bool)) []string {
+	t.Helper()
+	var s []string
+	for d := range seq {
+		s = append(s, d.String())
+	}
+
+	var first []string
+	for d := range seq {
+		first = append(first, d.String())
+		break
+	}
+	if len(s) > 0 {
+		assert(t, s[:1], first)
+	}
+
+	return s
+}
+
+var containerDIDs = []string{
+	"did:web:example.com",
+	"did:example:org:acme:bob",
+	"did:example:org:acme",
+	"did:example:org:acme#key-1",
+	"did:example:org:acmes",
+	"did:example:org:acme:alice#key-1",
+	"did:example:org:acme/path",
+	"did:example:org:acme?service=files",
+	"did:example:org",
+	"did:key:z6Mk",
+}
+
+func TestMap(t *testing.T) {
+	var m Map[int]
+	assert(t, 0, m.Len())
+
+	for i, input := range containerDIDs {
+		m.Put(mustParse(t, input), i)
+	}
+	assert(t, len(containerDIDs), m.Len())
+
+	for i, input := range containerDIDs {
+		value, ok := m.Get(mustParse(t, input))
+		assert(t, true, ok, "Input: %s", input)
+		assert(t, i, value, "Input: %s", input)
+	}
+
+	// keys are compared in their normal form
+	value, ok := m.Get(mustParse(t, "did:example:org:acm%65#key%2D1"))
+	assert(t, true, ok)
+	assert(t, 3, value)
+
+	m.Put(mustParse(t, "did:example:org:acm%65#key%2D1"), 42)
+	assert(t, len(containerDIDs), m.Len())
+	value, _ = m.Get(mustParse(t, "did:example:org:acme#key-1"))
+	assert(t, 42, value)
+
+	_, ok = m.Get(mustParse(t, "did:example:org:acme:carol"))
+	assert(t, false, ok)
+	_, ok = m.Get(mustParse(t, "did:other:org"))
+	assert(t, false, ok)
+
+	// iteration is in Compare order
+	var all []*DID
+	for d := range m.All() {
+		all = append(all, d)
+	}
+	assert(t, len(containerDIDs), len(all))
+	assert(t, true, sort.SliceIsSorted(all, func(i, j int) bool { return Compare(all[i], all[j]) < 0 }))
+
+	values := make(map[string]int)
+	for d, value := range m.All() {
+		values[d.String()] = value
+	}
+	assert(t, 42, values["did:example:org:acme#key-1"])
+	assert(t, 0, values["did:web:example.com"])
+
+	assert(t, false, m.Delete(mustParse(t, "did:example:org:acme%3Abob")))
+	assert(t, true, m.Delete(mustParse(t, "did:example:org:acme:bob")))
+	assert(t, false, m.Delete(mustParse(t, "did:example:org:acme:bob")))
+	assert(t, false, m.Delete(mustParse(t, "did:other:org")))
+	assert(t, len(containerDIDs)-1, m.Len())
+	_, ok = m.Get(mustParse(t, "did:example:org:acme:bob"))
+	assert(t, false, ok)
+
+	// the nodes left empty are removed
+	assert(t, true, m.Delete(mustParse(t, "did:key:z6Mk")))
+	assert(t, (*trieNode[int])(nil), m.root.children["key"])
+	assert(t, true, m.Delete(mustParse(t, "did:example:org:acme:alice#key-1")))
+	assert(t, (*trieNode[int])(nil), m.root.find([]string{"example", "org", "acme", "alice"}, false))
+	assert(t, true, m.root.find([]string{"example", "org", "acme"}, false) != nil)
+}
+
+func TestMapLookups(t *testing.T) {
+	var m Map[string]
+	for _, input := range containerDIDs {
+		m.Put(mustParse(t, input), input)
+	}
+
+	keys := func(seq func(func(*DID, string) bool)) func(func(*DID) bool) {
+		return func(yield func(*DID) bool) {
+			for d, value := range seq {
+				assert(t, d.String(), value)
+				if !yield(d) {
+					return
+				}
+			}
+		}
+	}
+
+	tests := []struct {
+		name     string
+		seq      func(func(*DID, string) bool)
+		expected []string
+	}{
+		{"Method example", m.Method("example"), []string{
+			"did:example:org",
+			"did:example:org:acme",
+			"did:example:org:acme#key-1",
+			"did:example:org:acme?service=files",
+			"did:example:org:acme/path",
+			"did:example:org:acme:alice#key-1",
+			"did:example:org:acme:bob",
+			"did:example:org:acmes",
+		}},
+		{"Method web", m.Method("web"), []string{"did:web:example.com"}},
+		{"Method other", m.Method("other"), nil},
+		{"Prefix did:example:org:acme", m.Prefix(mustParse(t, "did:example:org:acme#ignored")), []string{
+			"did:example:org:acme",
+			"did:example:org:acme#key-1",
+			"did:example:org:acme?service=files",
+			"did:example:org:acme/path",
+			"did:example:org:acme:alice#key-1",
+			"did:example:org:acme:bob",
+		}},
+		{"Prefix did:example:org:acme:alice", m.Prefix(mustParse(t, "did:example:org:acme:alice")), []string{
+			"did:example:org:acme:alice#key-1",
+		}},
+		{"Prefix did:example:org:acm", m.Prefix(mustParse(t, "did:example:org:acm")), nil},
+		{"Base did:example:org:acme", m.Base(mustParse(t, "did:example:org:acm%65#key-2")), []string{
+			"did:example:org:acme",
+			"did:example:org:acme#key-1",
+			"did:example:org:acme?service=files",
+			"did:example:org:acme/path",
+		}},
+		{"Base did:example:org:acme:alice", m.Base(mustParse(t, "did:example:org:acme:alice")), []string{
+			"did:example:org:acme:alice#key-1",
+		}},
+		{"Base did:example:org:acme:carol", m.Base(mustParse(t, "did:example:org:acme:carol")), nil},
+	}
+
+	for _, test := range tests {
+		assert(t, test.expected, didStrings(t, keys(test.seq)), "Lookup: %s", test.name)
+	}
+}
+
+func TestSet(t *testing.T) {
+	var s Set
+	for _, input := range containerDIDs {
+		assert(t, true, s.Add(mustParse(t, input)), "Input: %s", input)
+	}
+	assert(t, false, s.Add(mustParse(t, "did:example:org:acm%65#key%2D1")))
+	assert(t, len(containerDIDs), s.Len())
+
+	assert(t, true, s.Contains(mustParse(t, "did:example:org:acme?service=files")))
+	assert(t, false, s.Contains(mustParse(t, "did:example:org:acme?service=other")))
+
+	assert(t, []string{"did:web:example.com"}, didStrings(t, s.Method("web")))
+	assert(t, []string{"did:example:org:acme:alice#key-1"},
+		didStrings(t, s.Prefix(mustParse(t, "did:example:org:acme:alice"))))
+	assert(t, []string{"did:example:org:acmes"}, didStrings(t, s.Base(mustParse(t, "did:example:org:acmes#key-1"))))
+
+	all := didStrings(t, s.All())
+	assert(t, len(containerDIDs), len(all))
+	assert(t, "did:example:org", all[0])
+	assert(t, "did:web:example.com", all[len(all)-1])
+
+	assert(t, true, s.Remove(mustParse(t, "did:web:example.com")))
+	assert(t, false, s.Remove(mustParse(t, "did:web:example.com")))
+	assert(t, false, s.Contains(mustParse(t, "did:web:example.com")))
+	assert(t, len(containerDIDs)-1, s.Len())
+	assert(t, []string(nil), didStrings(t, s.Method("web")))
+}
+
+func TestSyncContainers(t *testing.T) {
+	var m SyncMap[int]
+	var s SyncSet
+	var wg sync.WaitGroup
+
+	for i, input := range containerDIDs {
+		d := mustParse(t, input)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Put(d, i)
+			s.Add(d)
+			s.Add(d)
+			for range m.Prefix(d) {
+			}
+			for range s.All() {
+			}
+			m.Get(d)
+			s.Contains(d)
+		}()
+	}
+	wg.Wait()
+
+	assert(t, len(containerDIDs), m.Len())
+	assert(t, len(containerDIDs), s.Len())
+	value, ok := m.Get(mustParse(t, "did:key:z6Mk"))
+	assert(t, true, ok)
+	assert(t, len(containerDIDs)-1, value)
+
+	// the map may be modified during an iteration
+	for d := range m.Method("example") {
+		assert(t, true, m.Delete(d))
+	}
+	for d := range s.Base(mustParse(t, "did:example:org:acme")) {
+		assert(t, true, s.Remove(d))
+	}
+	assert(t, []string{"did:key:z6Mk", "did:web:example.com"}, didStrings(t, func(yield func(*DID) bool) {
+		for d, value := range m.All() {
+			assert(t, true, value >= 0)
+			if !yield(d) {
+				return
+			}
+		}
+	}))
+	assert(t, len(containerDIDs)-4, s.Len())
+	assert(t, false, m.Delete(mustParse(t, "did:example:org")))
+	assert(t, true, s.Remove(mustParse(t, "did:example:org")))
+}
@@ -0,0 +1,129 @@
+package did
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// The URLs of the JSON-LD contexts that are bundled with this package, ContextV1 is the context of DID Core
+const (
+	ContextSecurityV1       = "https://w3id.org/security/v1"
+	ContextSecurityV2       = "https://w3id.org/security/v2"
+	ContextJWS2020          = "https://w3id.org/security/suites/jws-2020/v1"
+	ContextEd25519Suite2020 = "https://w3id.org/security/suites/ed25519-2020/v1"
+	ContextMultikeyV1       = "https://w3id.org/security/multikey/v1"
+)
+
+// ErrContextNotFound is returned by a DocumentLoader that does not have the context at a URL
+var ErrContextNotFound = errors.New("JSON-LD context not found")
+
+// bundledContexts are copies of the contexts that DID documents use the most, so that they can be
+// processed without fetching anything from the network
+//
+//go:embed contexts/*.jsonld
+var bundledContexts embed.FS
+
+// bundledContextFiles maps the URL of each bundled context to its file in bundledContexts
+var bundledContextFiles = map[string]string{
+	ContextV1:               "contexts/did-v1.jsonld",
+	ContextSecurityV1:       "contexts/security-v1.jsonld",
+	ContextSecurityV2:       "contexts/security-v2.jsonld",
+	ContextJWS2020:          "contexts/jws-2020-v1.jsonld",
+	ContextEd25519Suite2020: "contexts/ed25519-2020-v1.jsonld",
+	ContextMultikeyV1:       "contexts/multikey-v1.jsonld",
+}
+
+// A DocumentLoader loads the JSON-LD context at a URL
+// https://www.w3.org/TR/json-ld11-api/#loaddocumentcallback
+type DocumentLoader interface {
+	// LoadDocument returns the context at url, or an error that wraps ErrContextNotFound
+	LoadDocument(url string) (*RemoteDocument, error)
+}
+
+// A RemoteDocument is a JSON-LD context that was loaded by a DocumentLoader
+// https://www.w3.org/TR/json-ld11-api/#remotedocument
+type RemoteDocument struct {
+	// DocumentURL is the URL of the context
+	DocumentURL string
+
+	// Document is the decoded JSON of the context, a map[string]interface{} with an @context key,
+	// it may be shared by all the documents loaded from the same URL and must not be modified
+	Document interface{}
+}
+
+// An OfflineLoader is a DocumentLoader that never fetches anything from the network, it loads the contexts
+// that are bundled with this package, and the contexts that were added to it with AddContext.
+// The zero value of an OfflineLoader only loads the bundled contexts. It is safe for concurrent use.
+type OfflineLoader struct {
+	mu       sync.RWMutex
+	contexts map[string]interface{}
+}
+
+// DefaultLoader is the OfflineLoader that is used when no DocumentLoader is given
+var DefaultLoader = &OfflineLoader{}
+
+// AddContext adds the context at url to the loader, data is its JSON. A context that was added
+// replaces a bundled context with the same URL.
+func (l *OfflineLoader) AddContext(url string, data []byte) error {
+	document, err := decodeContext(data)
+	if err != nil {
+		return fmt.Errorf("did: JSON-LD context %s: %w", url, err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.contexts == nil {
+		l.contexts = make(map[string]interface{})
+	}
+	l.contexts[url] = document
+	return nil
+}
+
+// LoadDocument implements the DocumentLoader interface, it returns an error that wraps ErrContextNotFound
+// if the context at url is neither bundled nor added
+func (l *OfflineLoader) LoadDocument(url string) (*RemoteDocument, error) {
+	l.mu.RLock()
+	document, ok := l.contexts[url]
+	l.mu.RUnlock()
+
+	if !ok {
+		document, ok = loadBundledContexts()[url]
+		if !ok {
+			return nil, fmt.Errorf("did: %s: %w", url, ErrContextNotFound)
+		}
+	}
+
+	return &RemoteDocument{DocumentURL: url, Document: document}, nil
+}
+
+// loadBundledContexts decodes the bundled contexts once, by URL, the tests check that they are valid
+var loadBundledContexts = sync.OnceValue(func() map[string]interface{} {
+	contexts := make(map[string]interface{}, len(bundledContextFiles))
+	for url, file := range bundledContextFiles {
+		data, err := bundledContexts.ReadFile(file)
+		if err != nil {
+			panic(err)
+		}
+		if contexts[url], err = decodeContext(data); err != nil {
+			panic(fmt.Sprintf("did: bundled JSON-LD context %s: %v", url, err))
+		}
+	}
+	return contexts
+})
+
+// decodeContext decodes the JSON of a context, which must be a map with an @context key
+func decodeContext(data []byte) (interface{}, error) {
+	var document map[string]interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if _, ok := document["@context"]; !ok {
+		return nil, errors.New("JSON-LD context does not have an @context key")
+	}
+
+	return document, nil
+}
@@ -0,0 +1,54 @@
+package did
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBundledContexts(t *testing.T) {
+	assert(t, len(bundledContextFiles), len(loadBundledContexts()))
+
+	var loader OfflineLoader
+	for url := range bundledContextFiles {
+		document, err := loader.LoadDocument(url)
+		assert(t, nil, err, "URL: %s", url)
+		assert(t, url, document.DocumentURL)
+
+		m, ok := document.Document.(map[string]interface{})
+		assert(t, true, ok, "URL: %s", url)
+
+		// every bundled context can be processed offline
+		checker := &termChecker{loader: &loader}
+		_, err = checker.process(&activeContext{terms: map[string]termDefinition{}}, m["@context"], "", []string{url})
+		assert(t, nil, err, "URL: %s", url)
+	}
+
+	_, err := DefaultLoader.LoadDocument("https://www.w3.org/2018/credentials/v1")
+	assert(t, true, errors.Is(err, ErrContextNotFound))
+}
+
+func TestOfflineLoaderAddContext(t *testing.T) {
+	var loader OfflineLoader
+
+	err := loader.AddContext("https://example.com/v1", []byte(`{"@context": {"name": "https://schema.org/name"}}`))
+	assert(t, nil, err)
+
+	document, err := loader.LoadDocument("https://example.com/v1")
+	assert(t, nil, err)
+	assert(t, map[string]interface{}{"@context": map[string]interface{}{"name": "https://schema.org/name"}},
+		document.Document)
+
+	// an added context replaces a bundled one
+	err = loader.AddContext(ContextV1, []byte(`{"@context": {}}`))
+	assert(t, nil, err)
+	document, err = loader.LoadDocument(ContextV1)
+	assert(t, nil, err)
+	assert(t, map[string]interface{}{"@context": map[string]interface{}{}}, document.Document)
+
+	document, err = DefaultLoader.LoadDocument(ContextV1)
+	assert(t, nil, err)
+	assert(t, true, len(document.Document.(map[string]interface{})["@context"].(map[string]interface{})) > 2)
+
+	assert(t, false, loader.AddContext("https://example.com/v2", []byte(`{"name": "x"}`)) == nil)
+	assert(t, false, loader.AddContext("https://example.com/v2", []byte(`{`)) == nil)
+}
@@ -1,10 +1,10 @@
 // Package did is a set of tools to work with Decentralized Identifiers (DIDs) as described
-// in the DID spec https://w3c.github.io/did-core/
+// in the DID spec https://www.w3.org/TR/did-core/
 package did
 
 import (
-	"fmt"
 	"strings"
+	"sync"
 )
 
 // Param represents a parsed DID param,
@@ -46,15 +46,19 @@ type DID struct {
 	Method string
 
 	// The method-specific-id component of a DID
-	// method-specific-id = *idchar *( ":" *idchar )
+	// method-specific-id = *( *idchar ":" ) 1*idchar
 	ID string
 
 	// method-specific-id may be composed of multiple `:` separated idstrings
 	IDStrings []string
 
 	// DID URL
+	// did-url = did path-abempty [ "?" query ] [ "#" fragment ]
+	// did-url may contain a path, query, and fragment
+	//
+	// Params are only populated when parsing with GrammarLegacyCCG, the CCG draft of the spec allowed
+	// a did-url to contain multiple `;` separated params before its path, DID Core 1.0 removed them
 	// did-url = did *( ";" param ) path-abempty [ "?" query ] [ "#" fragment ]
-	// did-url may contain multiple params, a path, query, and fragment
 	Params []Param
 
 	// DID Path, the portion of a DID reference that follows the first forward slash character.
@@ -73,18 +77,86 @@ type DID struct {
 	// DID Fragment, the portion of a DID reference that follows the first hash sign character ("#")
 	// https://w3c.github.io/did-core/#fragment
 	Fragment string
+
+	// RelativePath is true for a relative DID URL reference whose Path does not begin with a `/`,
+	// ex- the reference `keys/1` as opposed to `/keys/1`. The Path of a DID is always absolute.
+	// https://tools.ietf.org/html/rfc3986#section-4.2
+	RelativePath bool
+}
+
+// Grammar selects the version of the DID syntax that the parser implements
+type Grammar int
+
+const (
+	// GrammarCore10 is the DID syntax of the W3C DID Core 1.0 recommendation, it is described in did.abnf
+	// https://www.w3.org/TR/did-core/#did-syntax
+	GrammarCore10 Grammar = iota
+
+	// GrammarLegacyCCG is the DID syntax of the W3C CCG draft that preceded DID Core, it allows `;`
+	// separated params, does not allow pct-encoded idchars or empty idstrings, and requires the first
+	// path segment to be non empty
+	// https://w3c-ccg.github.io/did-spec/#the-generic-did-scheme
+	GrammarLegacyCCG
+)
+
+// ParseOptions configure the behaviour of ParseWithOptions.
+// The limits bound the work done and the memory allocated to parse untrusted input,
+// a limit that is zero or negative is not enforced. An input that exceeds a limit is rejected with a
+// ParseError that wraps ErrLimitExceeded.
+type ParseOptions struct {
+	// Grammar is the version of the DID syntax to parse, the zero value is GrammarCore10
+	Grammar Grammar
+
+	// MaxLength is the maximum length of the input in bytes
+	MaxLength int
+
+	// MaxIDStrings is the maximum number of `:` separated idstrings in the method-specific-id
+	MaxIDStrings int
+
+	// MaxPathSegments is the maximum number of `/` separated path segments
+	MaxPathSegments int
+
+	// MaxParams is the maximum number of `;` separated params, which are only parsed by GrammarLegacyCCG
+	MaxParams int
+
+	// AllowedMethods is the list of DID methods that are accepted, if it is empty all methods are accepted.
+	// A DID with any other method is rejected with a ParseError that wraps ErrMethodNotAllowed.
+	AllowedMethods []string
+
+	// ValidateMethodSpecificID validates the method-specific-id with the MethodSyntax registered for the method
+	// of the DID, a DID whose method is not registered is not validated. A method-specific-id that is not valid
+	// is rejected with a ParseError that wraps ErrInvalidMethodSpecificID.
+	ValidateMethodSpecificID bool
 }
 
 // the parsers internal state
 type parser struct {
-	input        string // input to the parser
-	currentIndex int    // index in the input which the parser is currently processing
-	out          *DID   // the output DID that the parser will assemble as it steps through its state machine
-	err          error  // an error in the parser state machine
+	input        string       // input to the parser
+	currentIndex int          // index in the input which the parser is currently processing
+	out          *DID         // the output DID that the parser will assemble as it steps through its state machine
+	err          error        // an error in the parser state machine
+	opts         ParseOptions // options that change what the parser accepts
+	idStart      int          // index in the input at which the method-specific-id begins
+	idEnd        int          // index in the input at which the method-specific-id ends
+	pathStart    int          // index in the input at which the path begins, -1 if there is no path
+	pathEnd      int          // index in the input at which the path ends
+	validateOnly bool         // if true, steps do not collect IDStrings, PathSegments and Params
+	scratch      DID          // the output of Validate, which is discarded
+	spans        *Spans       // if not nil, steps record the span of each component they parse
+	idStrings    int          // number of idstrings parsed, to enforce opts.MaxIDStrings
+	pathSegments int          // number of path segments parsed, to enforce opts.MaxPathSegments
+	params       int          // number of params parsed, to enforce opts.MaxParams
 }
 
 // a step in the parser state machine that returns the next step
-type parserStep func() parserStep
+type parserStep func(p *parser) parserStep
+
+// parsers are reused to avoid an allocation for every parse
+var parserPool = sync.Pool{
+	New: func() interface{} {
+		return new(parser)
+	},
+}
 
 // IsURL returns true if a DID has a Path, a Query or a Fragment
 // https://w3c-ccg.github.io/did-spec/#dfn-did-reference
@@ -97,6 +169,11 @@ func (d *DID) IsURL() bool {
 func (d *DID) String() string {
 	var buf strings.Builder
 
+	if d.Method == "" && d.ID == "" && len(d.IDStrings) == 0 && len(d.Params) == 0 {
+		// without a Method or ID, d is a relative DID URL reference
+		return d.referenceString()
+	}
+
 	// write the did: prefix
 	buf.WriteString("did:") // nolint, returned error is always nil
 
@@ -161,9 +238,79 @@ func (d *DID) String() string {
 }
 
 // Parse parses the input string into a DID structure.
+// The input must conform to the DID Core 1.0 syntax, use ParseWithOptions to parse legacy DIDs.
 func Parse(input string) (*DID, error) {
-	// intialize the parser state
-	p := &parser{input: input, out: &DID{}}
+	return ParseWithOptions(input, ParseOptions{})
+}
+
+// ParseWithOptions parses the input string into a DID structure using the given options.
+func ParseWithOptions(input string, opts ParseOptions) (*DID, error) {
+	d := &DID{}
+
+	err := parseInto(d, input, opts, nil, checkLength)
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// ParseBytes parses the input bytes into a DID structure. The DID does not retain a reference to input.
+func ParseBytes(input []byte) (*DID, error) {
+	return Parse(string(input))
+}
+
+// ParseInto parses the input string into dst, which is overwritten.
+// The storage of dst.IDStrings, dst.PathSegments and dst.Params is reused, so they must not be retained
+// by the caller between calls, and the strings in dst are substrings of input. When dst is reused,
+// ParseInto does not allocate. If the input is not a valid DID, dst is reset and the error is returned.
+func ParseInto(dst *DID, input string) error {
+	return parseInto(dst, input, ParseOptions{}, nil, checkLength)
+}
+
+// Validate checks that the input string is a valid DID or DID URL without allocating,
+// it returns the same error as Parse would.
+func Validate(input string) error {
+	p := parserPool.Get().(*parser)
+	*p = parser{input: input, validateOnly: true}
+	p.out = &p.scratch
+
+	p.run(checkLength)
+	err := p.err
+
+	// don't hold on to the input while the parser is in the pool
+	*p = parser{}
+	parserPool.Put(p)
+
+	return err
+}
+
+// parseInto resets dst, keeping the storage of its slices, and parses the input into it
+// by running the parser state machine from the given step, the spans of components are recorded
+// in spans if it is not nil
+func parseInto(dst *DID, input string, opts ParseOptions, spans *Spans, start parserStep) error {
+	*dst = DID{IDStrings: dst.IDStrings[:0], PathSegments: dst.PathSegments[:0], Params: dst.Params[:0]}
+
+	p := parserPool.Get().(*parser)
+	*p = parser{input: input, out: dst, opts: opts, spans: spans}
+
+	p.run(start)
+	err := p.err
+
+	// don't hold on to the input or dst while the parser is in the pool
+	*p = parser{}
+	parserPool.Put(p)
+
+	if err != nil {
+		*dst = DID{IDStrings: dst.IDStrings[:0], PathSegments: dst.PathSegments[:0], Params: dst.Params[:0]}
+	}
+
+	return err
+}
+
+// run runs the parser state machine starting at the given step, p.err is set if the input is not valid
+func (p *parser) run(start parserStep) {
+	p.pathStart = -1
 
 	// the parser state machine is implemented as a loop over parser steps
 	// steps increment p.currentIndex as they consume the input, each step returns the next step to run
@@ -172,24 +319,38 @@ func Parse(input string) (*DID, error) {
 	// This design is based on this talk from Rob Pike, although the talk focuses on lexical scanning,
 	// the DID grammar is simple enough for us to combine lexing and parsing into one lexerless parse
 	// http://www.youtube.com/watch?v=HxaD_trXwRE
-	parserState := p.checkLength
+	parserState := start
 	for parserState != nil {
-		parserState = parserState()
+		parserState = parserState(p)
 	}
 
-	// If one of the steps added an err to the parser state, exit. Return nil and the error.
-	err := p.err
-	if err != nil {
-		return nil, err
+	// If one of the steps added an err to the parser state, exit.
+	if p.err != nil {
+		return
 	}
 
-	// join IDStrings with : to make up ID
-	p.out.ID = strings.Join(p.out.IDStrings[:], ":")
+	// the idstrings are contiguous in the input, ID is the substring that contains all of them
+	p.out.ID = p.input[p.idStart:p.idEnd]
+
+	// the path segments are contiguous in the input, Path is the substring that contains all of them
+	if p.pathStart >= 0 {
+		p.out.Path = p.input[p.pathStart:p.pathEnd]
+	}
 
-	// join PathSegments with / to make up Path
-	p.out.Path = strings.Join(p.out.PathSegments[:], "/")
+	if p.opts.ValidateMethodSpecificID && p.out.Method != "" {
+		if syntax, ok := lookupMethod(p.out.Method); ok {
+			_, p.err = parseMethodSpecific(syntax, p.out.Method, p.out.ID, p.input, p.idStart)
+		}
+	}
 
-	return p.out, nil
+	if p.spans != nil {
+		if p.idEnd > 0 {
+			p.spans.ID = Span{p.idStart, p.idEnd}
+		}
+		if p.pathStart >= 0 {
+			p.spans.Path = Span{p.pathStart, p.pathEnd}
+		}
+	}
 }
 
 // checkLength is a parserStep that checks if the input length is atleast 7
@@ -201,28 +362,41 @@ func Parse(input string) (*DID, error) {
 // i.e atleast 7 chars
 // The current specification does not take a position on maximum length of a DID.
 // https://w3c-ccg.github.io/did-spec/#upper-limits-on-did-character-length
-func (p *parser) checkLength() parserStep {
+func checkLength(p *parser) parserStep {
 	inputLength := len(p.input)
 
 	if inputLength < 7 {
-		return p.errorf(inputLength, "input length is less than 7")
+		return p.errorf(inputLength, ComponentScheme, "did", ErrTooShort, "input length is less than 7")
 	}
 
-	return p.parseScheme
+	if p.opts.MaxLength > 0 && inputLength > p.opts.MaxLength {
+		return p.errorf(p.opts.MaxLength, ComponentScheme, "did", ErrLimitExceeded,
+			"input length is more than %d", p.opts.MaxLength)
+	}
+
+	return parseScheme
 }
 
 // parseScheme is a parserStep that validates that the input begins with 'did:'
-func (p *parser) parseScheme() parserStep {
+func parseScheme(p *parser) parserStep {
 
 	currentIndex := 3 // 4 bytes in 'did:', i.e index 3
 
 	// the grammar requires `did:` prefix
 	if p.input[:currentIndex+1] != "did:" {
-		return p.errorf(currentIndex, "input does not begin with 'did:' prefix")
+		// report the offset of the first byte that does not match the prefix
+		index := 0
+		for p.input[index] == "did:"[index] {
+			index = index + 1
+		}
+		return p.errorf(index, ComponentScheme, "did", ErrInvalidScheme, "input does not begin with 'did:' prefix")
 	}
 
 	p.currentIndex = currentIndex
-	return p.parseMethod
+	if p.spans != nil {
+		p.spans.Scheme = Span{0, currentIndex}
+	}
+	return parseMethod
 }
 
 // parseMethod is a parserStep that extracts the DID Method
@@ -230,7 +404,7 @@ func (p *parser) parseScheme() parserStep {
 //   did        = "did:" method ":" specific-idstring
 //   method     = 1*methodchar
 //   methodchar = %x61-7A / DIGIT ; 61-7A is a-z in US-ASCII
-func (p *parser) parseMethod() parserStep {
+func parseMethod(p *parser) parserStep {
 	input := p.input
 	inputLength := len(input)
 	currentIndex := p.currentIndex + 1
@@ -242,7 +416,8 @@ func (p *parser) parseMethod() parserStep {
 	for {
 		if currentIndex == inputLength {
 			// we got to the end of the input and didn't find a second ':'
-			return p.errorf(currentIndex, "input does not have a second `:` marking end of method name")
+			return p.errorf(currentIndex, ComponentMethod, "did", ErrMissingMethod,
+				"input does not have a second `:` marking end of method name")
 		}
 
 		// read the input character at currentIndex
@@ -252,42 +427,74 @@ func (p *parser) parseMethod() parserStep {
 			// we've found the second : in the input that marks the end of the method
 			if currentIndex == startIndex {
 				// return error is method is empty, ex- did::1234
-				return p.errorf(currentIndex, "method is empty")
+				return p.errorf(currentIndex, ComponentMethod, "method-name", ErrMissingMethod, "method is empty")
 			}
 			break
 		}
 
 		// as per the grammar method can only be made of digits 0-9 or small letters a-z
 		if isNotDigit(char) && isNotSmallLetter(char) {
-			return p.errorf(currentIndex, "character is not a-z OR 0-9")
+			return p.errorf(currentIndex, ComponentMethod, "method-char", ErrInvalidCharacter, "character is not a-z OR 0-9")
 		}
 
 		// move to the next char
 		currentIndex = currentIndex + 1
 	}
 
+	if len(p.opts.AllowedMethods) > 0 && !p.isAllowedMethod(input[startIndex:currentIndex]) {
+		return p.errorf(startIndex, ComponentMethod, "method-name", ErrMethodNotAllowed, "method is not allowed")
+	}
+
 	// set parser state
 	p.currentIndex = currentIndex
 	p.out.Method = input[startIndex:currentIndex]
+	p.idStart = currentIndex + 1
+	if p.spans != nil {
+		p.spans.Method = Span{startIndex, currentIndex}
+	}
 
 	// method is followed by specific-idstring, parse that next
-	return p.parseID
+	return parseID
 }
 
-// parseID is a parserStep that extracts : separated idstrings that are part of a specific-idstring
+// isAllowedMethod returns true if method is one of the AllowedMethods of the parser options
+func (p *parser) isAllowedMethod(method string) bool {
+	for _, allowed := range p.opts.AllowedMethods {
+		if method == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// parseID is a parserStep that extracts : separated idstrings that are part of a method-specific-id
 // and adds them to p.out.IDStrings
 // from the grammar:
+//   method-specific-id = *( *idchar ":" ) 1*idchar
+//   idchar             = ALPHA / DIGIT / "." / "-" / "_" / pct-encoded
+// i.e. idstrings may be empty, except for the last one
+// GrammarLegacyCCG does not allow empty idstrings or pct-encoded chars, and allows a param to follow:
 //   specific-idstring = idstring *( ":" idstring )
 //   idstring          = 1*idchar
-//   idchar            = ALPHA / DIGIT / "." / "-"
-// p.out.IDStrings is later concatented by the Parse function before it returns.
-func (p *parser) parseID() parserStep {
+//   idchar            = ALPHA / DIGIT / "." / "-" / "_"
+// the span of the method-specific-id is later used by the Parse function to set ID before it returns.
+// nolint: gocyclo
+func parseID(p *parser) parserStep {
 	input := p.input
 	inputLength := len(input)
 	currentIndex := p.currentIndex + 1
 	startIndex := currentIndex
+	legacy := p.opts.Grammar == GrammarLegacyCCG
 
+	p.idStrings = p.idStrings + 1
+	if p.opts.MaxIDStrings > 0 && p.idStrings > p.opts.MaxIDStrings {
+		return p.errorf(startIndex, ComponentMethodSpecificID, "method-specific-id", ErrLimitExceeded,
+			"method-specific-id has more than %d idstrings", p.opts.MaxIDStrings)
+	}
+
+	var indexIncrement int
 	var next parserStep
+	var percentEncoded bool
 
 	for {
 		if currentIndex == inputLength {
@@ -300,55 +507,78 @@ func (p *parser) parseID() parserStep {
 
 		if char == ':' {
 			// encountered : input may have another idstring, parse ID again
-			next = p.parseID
+			next = parseID
 			break
 		}
 
-		if char == ';' {
+		if char == ';' && legacy {
 			// encountered ; input may have a parameter, parse that next
-			next = p.parseParamName
+			next = parseParamName
 			break
 		}
 
 		if char == '/' {
 			// encountered / input may have a path following specific-idstring, parse that next
-			next = p.parsePath
+			next = parsePath
 			break
 		}
 
 		if char == '?' {
 			// encountered ? input may have a query following specific-idstring, parse that next
-			next = p.parseQuery
+			next = parseQuery
 			break
 		}
 
 		if char == '#' {
 			// encountered # input may have a fragment following specific-idstring, parse that next
-			next = p.parseFragment
+			next = parseFragment
 			break
 		}
 
+		if char == '%' && !legacy {
+			// a % must be followed by 2 hex digits
+			if (currentIndex+2 >= inputLength) ||
+				isNotHexDigit(input[currentIndex+1]) ||
+				isNotHexDigit(input[currentIndex+2]) {
+				return p.errorf(currentIndex, ComponentMethodSpecificID, "pct-encoded", ErrInvalidPercentEncoding,
+					"%% is not followed by 2 hex digits")
+			}
+			// if we got here, we're dealing with percent encoded char, jump three chars
+			percentEncoded = true
+			indexIncrement = 3
+		} else {
+			// not percent encoded
+			percentEncoded = false
+			indexIncrement = 1
+		}
+
 		// make sure current char is a valid idchar
-		// idchar = ALPHA / DIGIT / "." / "-"
-		if isNotValidIDChar(char) {
-			return p.errorf(currentIndex, "byte is not ALPHA OR DIGIT OR '.' OR '-'")
+		// idchar = ALPHA / DIGIT / "." / "-" / "_" / pct-encoded
+		if !percentEncoded && isNotValidIDChar(char) {
+			return p.errorf(currentIndex, ComponentMethodSpecificID, "idchar", ErrInvalidCharacter,
+				"byte is not ALPHA OR DIGIT OR '.' OR '-' OR '_'")
 		}
 
 		// move to the next char
-		currentIndex = currentIndex + 1
+		currentIndex = currentIndex + indexIncrement
 	}
 
-	if currentIndex == startIndex {
-		// idstring length is zero
-		// from the grammar:
-		//   idstring = 1*idchar
-		// return error because idstring is empty, ex- did:a::123:456
-		return p.errorf(currentIndex, "idstring must be atleast one char long")
+	// an empty idstring is only allowed by DID Core if another idstring follows it
+	// return error because idstring is empty, ex- did:a:123:, or with the legacy grammar did:a::123:456
+	if currentIndex == startIndex && (legacy || currentIndex == inputLength || input[currentIndex] != ':') {
+		return p.errorf(currentIndex, ComponentMethodSpecificID, "method-specific-id", ErrEmptyIDString,
+			"idstring must be atleast one char long")
 	}
 
 	// set parser state
 	p.currentIndex = currentIndex
-	p.out.IDStrings = append(p.out.IDStrings, input[startIndex:currentIndex])
+	p.idEnd = currentIndex
+	if !p.validateOnly {
+		p.out.IDStrings = append(p.out.IDStrings, input[startIndex:currentIndex])
+	}
+	if p.spans != nil {
+		p.spans.IDStrings = append(p.spans.IDStrings, Span{startIndex, currentIndex})
+	}
 
 	// return the next parser step
 	return next
@@ -360,10 +590,17 @@ func (p *parser) parseID() parserStep {
 //   param              = param-name [ "=" param-value ]
 //   param-name         = 1*param-char
 //   param-char         = ALPHA / DIGIT / "." / "-" / "_" / ":" / pct-encoded
-func (p *parser) parseParamName() parserStep {
+func parseParamName(p *parser) parserStep {
 	input := p.input
 	startIndex := p.currentIndex + 1
-	next := p.paramTransition()
+
+	p.params = p.params + 1
+	if p.opts.MaxParams > 0 && p.params > p.opts.MaxParams {
+		return p.errorf(startIndex, ComponentParam, "param", ErrLimitExceeded,
+			"did-url has more than %d params", p.opts.MaxParams)
+	}
+
+	next := paramTransition(p)
 	currentIndex := p.currentIndex
 
 	if currentIndex == startIndex {
@@ -371,11 +608,17 @@ func (p *parser) parseParamName() parserStep {
 		// from the grammar:
 		//   1*param-char
 		// return error because param-name is empty, ex- did:a::123:456;param-name
-		return p.errorf(currentIndex, "Param name must be at least one char long")
+		return p.errorf(currentIndex, ComponentParam, "param-name", ErrEmptyParamName,
+			"Param name must be at least one char long")
 	}
 
 	// Create a new param with the name
-	p.out.Params = append(p.out.Params, Param{Name: input[startIndex:currentIndex], Value: ""})
+	if !p.validateOnly {
+		p.out.Params = append(p.out.Params, Param{Name: input[startIndex:currentIndex], Value: ""})
+	}
+	if p.spans != nil {
+		p.spans.Params = append(p.spans.Params, ParamSpan{Name: Span{startIndex, currentIndex}})
+	}
 
 	// return the next parser step
 	return next
@@ -387,15 +630,20 @@ func (p *parser) parseParamName() parserStep {
 //   param              = param-name [ "=" param-value ]
 //   param-value         = 1*param-char
 //   param-char         = ALPHA / DIGIT / "." / "-" / "_" / ":" / pct-encoded
-func (p *parser) parseParamValue() parserStep {
+func parseParamValue(p *parser) parserStep {
 	input := p.input
 	startIndex := p.currentIndex + 1
-	next := p.paramTransition()
+	next := paramTransition(p)
 	currentIndex := p.currentIndex
 
 	// Get the last Param in the DID and append the value
 	// values may be empty according to the grammar- *param-char
-	p.out.Params[len(p.out.Params)-1].Value = input[startIndex:currentIndex]
+	if !p.validateOnly {
+		p.out.Params[len(p.out.Params)-1].Value = input[startIndex:currentIndex]
+	}
+	if p.spans != nil {
+		p.spans.Params[len(p.spans.Params)-1].Value = Span{startIndex, currentIndex}
+	}
 
 	// return the next parser step
 	return next
@@ -404,7 +652,7 @@ func (p *parser) parseParamValue() parserStep {
 // paramTransition is a parserStep that extracts and transitions a param-name or
 // param-value.
 // nolint: gocyclo
-func (p *parser) paramTransition() parserStep {
+func paramTransition(p *parser) parserStep {
 	input := p.input
 	inputLength := len(input)
 	currentIndex := p.currentIndex + 1
@@ -424,32 +672,32 @@ func (p *parser) paramTransition() parserStep {
 
 		if char == ';' {
 			// encountered : input may have another param, parse paramName again
-			next = p.parseParamName
+			next = parseParamName
 			break
 		}
 
 		// Separate steps for name and value?
 		if char == '=' {
 			// parse param value
-			next = p.parseParamValue
+			next = parseParamValue
 			break
 		}
 
 		if char == '/' {
 			// encountered / input may have a path following current param, parse that next
-			next = p.parsePath
+			next = parsePath
 			break
 		}
 
 		if char == '?' {
 			// encountered ? input may have a query following current param, parse that next
-			next = p.parseQuery
+			next = parseQuery
 			break
 		}
 
 		if char == '#' {
 			// encountered # input may have a fragment following current param, parse that next
-			next = p.parseFragment
+			next = parseFragment
 			break
 		}
 
@@ -458,7 +706,8 @@ func (p *parser) paramTransition() parserStep {
 			if (currentIndex+2 >= inputLength) ||
 				isNotHexDigit(input[currentIndex+1]) ||
 				isNotHexDigit(input[currentIndex+2]) {
-				return p.errorf(currentIndex, "%% is not followed by 2 hex digits")
+				return p.errorf(currentIndex, ComponentParam, "pct-encoded", ErrInvalidPercentEncoding,
+					"%% is not followed by 2 hex digits")
 			}
 			// if we got here, we're dealing with percent encoded char, jump three chars
 			percentEncoded = true
@@ -472,7 +721,8 @@ func (p *parser) paramTransition() parserStep {
 		// make sure current char is a valid param-char
 		// idchar = ALPHA / DIGIT / "." / "-"
 		if !percentEncoded && isNotValidParamChar(char) {
-			return p.errorf(currentIndex, "character is not allowed in param - %c", char)
+			return p.errorf(currentIndex, ComponentParam, "param-char", ErrInvalidCharacter,
+				"character is not allowed in param - %c", char)
 		}
 
 		// move to the next char
@@ -487,7 +737,7 @@ func (p *parser) paramTransition() parserStep {
 
 // parsePath is a parserStep that extracts a DID Path from a DID Reference
 // from the grammar:
-//   did-path      = segment-nz *( "/" segment )
+//   path-abempty  = *( "/" segment )
 //   segment       = *pchar
 //   segment-nz    = 1*pchar
 //   pchar         = unreserved / pct-encoded / sub-delims / ":" / "@"
@@ -495,12 +745,18 @@ func (p *parser) paramTransition() parserStep {
 //   pct-encoded   = "%" HEXDIG HEXDIG
 //   sub-delims    = "!" / "$" / "&" / "'" / "(" / ")" / "*" / "+" / "," / ";" / "="
 // nolint: gocyclo
-func (p *parser) parsePath() parserStep {
+func parsePath(p *parser) parserStep {
 	input := p.input
 	inputLength := len(input)
 	currentIndex := p.currentIndex + 1
 	startIndex := currentIndex
 
+	p.pathSegments = p.pathSegments + 1
+	if p.opts.MaxPathSegments > 0 && p.pathSegments > p.opts.MaxPathSegments {
+		return p.errorf(startIndex, ComponentPath, "path-abempty", ErrLimitExceeded,
+			"path has more than %d segments", p.opts.MaxPathSegments)
+	}
+
 	var indexIncrement int
 	var next parserStep
 	var percentEncoded bool
@@ -515,13 +771,19 @@ func (p *parser) parsePath() parserStep {
 
 		if char == '/' {
 			// encountered / input may have another path segment, try to parse that next
-			next = p.parsePath
+			next = parsePath
 			break
 		}
 
 		if char == '?' {
 			// encountered ? input may have a query following path, parse that next
-			next = p.parseQuery
+			next = parseQuery
+			break
+		}
+
+		if char == '#' {
+			// encountered # input may have a fragment following path, parse that next
+			next = parseFragment
 			break
 		}
 
@@ -530,7 +792,8 @@ func (p *parser) parsePath() parserStep {
 			if (currentIndex+2 >= inputLength) ||
 				isNotHexDigit(input[currentIndex+1]) ||
 				isNotHexDigit(input[currentIndex+2]) {
-				return p.errorf(currentIndex, "%% is not followed by 2 hex digits")
+				return p.errorf(currentIndex, ComponentPath, "pct-encoded", ErrInvalidPercentEncoding,
+					"%% is not followed by 2 hex digits")
 			}
 			// if we got here, we're dealing with percent encoded char, jump three chars
 			percentEncoded = true
@@ -543,24 +806,34 @@ func (p *parser) parsePath() parserStep {
 
 		// pchar = unreserved / pct-encoded / sub-delims / ":" / "@"
 		if !percentEncoded && isNotValidPathChar(char) {
-			return p.errorf(currentIndex, "character is not allowed in path")
+			return p.errorf(currentIndex, ComponentPath, "pchar", ErrInvalidCharacter, "character is not allowed in path")
 		}
 
 		// move to the next char
 		currentIndex = currentIndex + indexIncrement
 	}
 
-	if currentIndex == startIndex && len(p.out.PathSegments) == 0 {
+	if currentIndex == startIndex && p.pathStart < 0 && p.opts.Grammar == GrammarLegacyCCG {
 		// path segment length is zero
 		// first path segment must have atleast one character
-		// from the grammar
+		// from the legacy grammar
 		//   did-path = segment-nz *( "/" segment )
-		return p.errorf(currentIndex, "first path segment must have atleast one character")
+		return p.errorf(currentIndex, ComponentPath, "segment-nz", ErrEmptyPathSegment,
+			"first path segment must have atleast one character")
 	}
 
 	// update parser state
 	p.currentIndex = currentIndex
-	p.out.PathSegments = append(p.out.PathSegments, input[startIndex:currentIndex])
+	if p.pathStart < 0 {
+		p.pathStart = startIndex
+	}
+	p.pathEnd = currentIndex
+	if !p.validateOnly {
+		p.out.PathSegments = append(p.out.PathSegments, input[startIndex:currentIndex])
+	}
+	if p.spans != nil {
+		p.spans.PathSegments = append(p.spans.PathSegments, Span{startIndex, currentIndex})
+	}
 
 	return next
 }
@@ -572,7 +845,7 @@ func (p *parser) parsePath() parserStep {
 //   unreserved    = ALPHA / DIGIT / "-" / "." / "_" / "~"
 //   pct-encoded   = "%" HEXDIG HEXDIG
 //   sub-delims    = "!" / "$" / "&" / "'" / "(" / ")" / "*" / "+" / "," / ";" / "="
-func (p *parser) parseQuery() parserStep {
+func parseQuery(p *parser) parserStep {
 	input := p.input
 	inputLength := len(input)
 	currentIndex := p.currentIndex + 1
@@ -594,7 +867,7 @@ func (p *parser) parseQuery() parserStep {
 
 		if char == '#' {
 			// encountered # input may have a fragment following the query, parse that next
-			next = p.parseFragment
+			next = parseFragment
 			break
 		}
 
@@ -603,7 +876,8 @@ func (p *parser) parseQuery() parserStep {
 			if (currentIndex+2 >= inputLength) ||
 				isNotHexDigit(input[currentIndex+1]) ||
 				isNotHexDigit(input[currentIndex+2]) {
-				return p.errorf(currentIndex, "%% is not followed by 2 hex digits")
+				return p.errorf(currentIndex, ComponentQuery, "pct-encoded", ErrInvalidPercentEncoding,
+					"%% is not followed by 2 hex digits")
 			}
 			// if we got here, we're dealing with percent encoded char, jump three chars
 			percentEncoded = true
@@ -618,7 +892,8 @@ func (p *parser) parseQuery() parserStep {
 		// pchar = unreserved / pct-encoded / sub-delims / ":" / "@"
 		// isNotValidQueryOrFragmentChar checks for all the valid chars except pct-encoded
 		if !percentEncoded && isNotValidQueryOrFragmentChar(char) {
-			return p.errorf(currentIndex, "character is not allowed in query - %c", char)
+			return p.errorf(currentIndex, ComponentQuery, "query", ErrInvalidCharacter,
+				"character is not allowed in query - %c", char)
 		}
 
 		// move to the next char
@@ -628,6 +903,9 @@ func (p *parser) parseQuery() parserStep {
 	// update parser state
 	p.currentIndex = currentIndex
 	p.out.Query = input[startIndex:currentIndex]
+	if p.spans != nil {
+		p.spans.Query = Span{startIndex, currentIndex}
+	}
 
 	return next
 }
@@ -639,7 +917,7 @@ func (p *parser) parseQuery() parserStep {
 //   unreserved    = ALPHA / DIGIT / "-" / "." / "_" / "~"
 //   pct-encoded   = "%" HEXDIG HEXDIG
 //   sub-delims    = "!" / "$" / "&" / "'" / "(" / ")" / "*" / "+" / "," / ";" / "="
-func (p *parser) parseFragment() parserStep {
+func parseFragment(p *parser) parserStep {
 	input := p.input
 	inputLength := len(input)
 	currentIndex := p.currentIndex + 1
@@ -663,7 +941,8 @@ func (p *parser) parseFragment() parserStep {
 			if (currentIndex+2 >= inputLength) ||
 				isNotHexDigit(input[currentIndex+1]) ||
 				isNotHexDigit(input[currentIndex+2]) {
-				return p.errorf(currentIndex, "%% is not followed by 2 hex digits")
+				return p.errorf(currentIndex, ComponentFragment, "pct-encoded", ErrInvalidPercentEncoding,
+					"%% is not followed by 2 hex digits")
 			}
 			// if we got here, we're dealing with percent encoded char, jump three chars
 			percentEncoded = true
@@ -678,7 +957,8 @@ func (p *parser) parseFragment() parserStep {
 		// pchar = unreserved / pct-encoded / sub-delims / ":" / "@"
 		// isNotValidQueryOrFragmentChar checks for all the valid chars except pct-encoded
 		if !percentEncoded && isNotValidQueryOrFragmentChar(char) {
-			return p.errorf(currentIndex, "character is not allowed in fragment - %c", char)
+			return p.errorf(currentIndex, ComponentFragment, "fragment", ErrInvalidCharacter,
+				"character is not allowed in fragment - %c", char)
 		}
 
 		// move to the next char
@@ -688,6 +968,9 @@ func (p *parser) parseFragment() parserStep {
 	// update parser state
 	p.currentIndex = currentIndex
 	p.out.Fragment = input[startIndex:currentIndex]
+	if p.spans != nil {
+		p.spans.Fragment = Span{startIndex, currentIndex}
+	}
 
 	// no more parsing needed after a fragment,
 	// cause the state machine to exit by returning nil
@@ -697,9 +980,12 @@ func (p *parser) parseFragment() parserStep {
 // errorf is a parserStep that returns nil to cause the state machine to exit
 // before returning it sets the currentIndex and err field in parser state
 // other parser steps use this function to exit the state machine with an error
-func (p *parser) errorf(index int, format string, args ...interface{}) parserStep {
+// the error is a *ParseError that records the component and grammar rule being parsed,
+// and wraps err which should be one of the sentinel errors declared in errors.go
+func (p *parser) errorf(index int, component Component, rule string, err error,
+	format string, args ...interface{}) parserStep {
 	p.currentIndex = index
-	p.err = fmt.Errorf(format, args...)
+	p.err = newParseError(p.input, index, component, rule, err, format, args...)
 	return nil
 }
 
@@ -709,9 +995,10 @@ func (p *parser) errorf(index int, format string, args ...interface{}) parserSte
 
 // isNotValidIDChar returns true if a byte is not allowed in a ID
 // from the grammar:
-//   idchar = ALPHA / DIGIT / "." / "-"
+//   idchar = ALPHA / DIGIT / "." / "-" / "_" / pct-encoded
+// pct-encoded is not checked in this function
 func isNotValidIDChar(char byte) bool {
-	return isNotAlpha(char) && isNotDigit(char) && char != '.' && char != '-'
+	return isNotAlpha(char) && isNotDigit(char) && char != '.' && char != '-' && char != '_'
 }
 
 // isNotValidParamChar returns true if a byte is not allowed in a param-name
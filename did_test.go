@@ -1,6 +1,7 @@
 package did
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"reflect"
@@ -157,6 +158,9 @@ func TestString(t *testing.T) {
 }
 
 func TestParse(t *testing.T) {
+	parseLegacy := func(input string) (*DID, error) {
+		return ParseWithOptions(input, ParseOptions{Grammar: GrammarLegacyCCG})
+	}
 
 	t.Run("returns error if input is empty", func(t *testing.T) {
 		_, err := Parse("")
@@ -184,7 +188,28 @@ func TestParse(t *testing.T) {
 		assert(t, false, err == nil)
 	})
 
-	t.Run("returns error if idstring is empty", func(t *testing.T) {
+	t.Run("returns error if last idstring is empty", func(t *testing.T) {
+		dids := []string{
+			"did:a:123:456:",
+			"did:a:123:/abc",
+			"did:a:123:?abc",
+			"did:a:123:#abc",
+			"did:a:123::",
+		}
+		for _, did := range dids {
+			_, err := Parse(did)
+			assert(t, false, err == nil, "Input: %s", did)
+		}
+	})
+
+	t.Run("succeeds if an idstring other than the last is empty", func(t *testing.T) {
+		d, err := Parse("did:a::123::456")
+		assert(t, nil, err)
+		assert(t, []string{"", "123", "", "456"}, d.IDStrings)
+		assert(t, ":123::456", d.ID)
+	})
+
+	t.Run("returns error if idstring is empty with legacy grammar", func(t *testing.T) {
 		dids := []string{
 			"did:a::123:456",
 			"did:a:123::456",
@@ -193,7 +218,7 @@ func TestParse(t *testing.T) {
 			"did:a:123:#abc",
 		}
 		for _, did := range dids {
-			_, err := Parse(did)
+			_, err := parseLegacy(did)
 			assert(t, false, err == nil, "Input: %s", did)
 		}
 	})
@@ -252,35 +277,78 @@ func TestParse(t *testing.T) {
 		assert(t, false, err == nil)
 	})
 
+	t.Run("succeeds with underscore in ID", func(t *testing.T) {
+		d, err := Parse("did:a:1_2:_3")
+		assert(t, nil, err)
+		assert(t, "1_2:_3", d.ID)
+	})
+
+	t.Run("succeeds with percent encoded chars in ID", func(t *testing.T) {
+		d, err := Parse("did:web:example.com%3A3000:user%2Fa")
+		assert(t, nil, err)
+		assert(t, "example.com%3A3000:user%2Fa", d.ID)
+		assert(t, []string{"example.com%3A3000", "user%2Fa"}, d.IDStrings)
+	})
+
+	t.Run("returns error if % in ID is not followed by 2 hex chars", func(t *testing.T) {
+		dids := []string{
+			"did:a:123%",
+			"did:a:123%a",
+			"did:a:123%A!",
+			"did:a:%zz:123",
+		}
+		for _, did := range dids {
+			_, err := Parse(did)
+			assert(t, false, err == nil, "Input: %s", did)
+		}
+	})
+
+	t.Run("returns error if ID has percent encoded chars with legacy grammar", func(t *testing.T) {
+		_, err := parseLegacy("did:a:123%20")
+		assert(t, false, err == nil)
+	})
+
+	t.Run("returns error if input has params", func(t *testing.T) {
+		dids := []string{
+			"did:a:123:456;service=agent",
+			"did:a:123:456;foo:bar",
+			"did:a:123:456;",
+		}
+		for _, did := range dids {
+			_, err := Parse(did)
+			assert(t, false, err == nil, "Input: %s", did)
+		}
+	})
+
 	t.Run("returns error if param name is empty", func(t *testing.T) {
-		_, err := Parse("did:a:123:456;")
+		_, err := parseLegacy("did:a:123:456;")
 		assert(t, false, err == nil)
 	})
 
 	t.Run("returns error if Param name has an invalid char", func(t *testing.T) {
-		_, err := Parse("did:a:123:456;serv&ce")
+		_, err := parseLegacy("did:a:123:456;serv&ce")
 		assert(t, false, err == nil)
 	})
 
 	t.Run("returns error if Param value has an invalid char", func(t *testing.T) {
-		_, err := Parse("did:a:123:456;service=ag&nt")
+		_, err := parseLegacy("did:a:123:456;service=ag&nt")
 		assert(t, false, err == nil)
 	})
 
 	t.Run("returns error if Param name has an invalid percent encoded", func(t *testing.T) {
-		_, err := Parse("did:a:123:456;ser%2ge")
+		_, err := parseLegacy("did:a:123:456;ser%2ge")
 		assert(t, false, err == nil)
 	})
 
 	t.Run("returns error if Param does not exist for value", func(t *testing.T) {
-		_, err := Parse("did:a:123:456;=value")
+		_, err := parseLegacy("did:a:123:456;=value")
 		assert(t, false, err == nil)
 	})
 
 	// nolint: dupl
 	// test for params look similar to linter
 	t.Run("succeeds to extract generic param with name and value", func(t *testing.T) {
-		d, err := Parse("did:a:123:456;service==agent")
+		d, err := parseLegacy("did:a:123:456;service==agent")
 		assert(t, nil, err)
 		assert(t, 1, len(d.Params))
 		assert(t, "service=agent", d.Params[0].String())
@@ -291,7 +359,7 @@ func TestParse(t *testing.T) {
 	// nolint: dupl
 	// test for params look similar to linter
 	t.Run("succeeds to extract generic param with name only", func(t *testing.T) {
-		d, err := Parse("did:a:123:456;service")
+		d, err := parseLegacy("did:a:123:456;service")
 		assert(t, nil, err)
 		assert(t, 1, len(d.Params))
 		assert(t, "service", d.Params[0].String())
@@ -302,7 +370,7 @@ func TestParse(t *testing.T) {
 	// nolint: dupl
 	// test for params look similar to linter
 	t.Run("succeeds to extract generic param with name only and empty param", func(t *testing.T) {
-		d, err := Parse("did:a:123:456;service=")
+		d, err := parseLegacy("did:a:123:456;service=")
 		assert(t, nil, err)
 		assert(t, 1, len(d.Params))
 		assert(t, "service", d.Params[0].String())
@@ -313,7 +381,7 @@ func TestParse(t *testing.T) {
 	// nolint: dupl
 	// test for params look similar to linter
 	t.Run("succeeds to extract method param with name and value", func(t *testing.T) {
-		d, err := Parse("did:a:123:456;foo:bar=baz")
+		d, err := parseLegacy("did:a:123:456;foo:bar=baz")
 		assert(t, nil, err)
 		assert(t, 1, len(d.Params))
 		assert(t, "foo:bar=baz", d.Params[0].String())
@@ -324,7 +392,7 @@ func TestParse(t *testing.T) {
 	// nolint: dupl
 	// test for params look similar to linter
 	t.Run("succeeds to extract method param with name only", func(t *testing.T) {
-		d, err := Parse("did:a:123:456;foo:bar")
+		d, err := parseLegacy("did:a:123:456;foo:bar")
 		assert(t, nil, err)
 		assert(t, 1, len(d.Params))
 		assert(t, "foo:bar", d.Params[0].String())
@@ -335,7 +403,7 @@ func TestParse(t *testing.T) {
 	// nolint: dupl
 	// test for params look similar to linter
 	t.Run("succeeds with percent encoded chars in param name and value", func(t *testing.T) {
-		d, err := Parse("did:a:123:456;serv%20ice=val%20ue")
+		d, err := parseLegacy("did:a:123:456;serv%20ice=val%20ue")
 		assert(t, nil, err)
 		assert(t, 1, len(d.Params))
 		assert(t, "serv%20ice=val%20ue", d.Params[0].String())
@@ -346,7 +414,7 @@ func TestParse(t *testing.T) {
 	// nolint: dupl
 	// test for params look similar to linter
 	t.Run("succeeds to extract multiple generic params with name only", func(t *testing.T) {
-		d, err := Parse("did:a:123:456;foo;bar")
+		d, err := parseLegacy("did:a:123:456;foo;bar")
 		assert(t, nil, err)
 		assert(t, 2, len(d.Params))
 		assert(t, "foo", d.Params[0].Name)
@@ -358,7 +426,7 @@ func TestParse(t *testing.T) {
 	// nolint: dupl
 	// test for params look similar to linter
 	t.Run("succeeds to extract multiple params with names and values", func(t *testing.T) {
-		d, err := Parse("did:a:123:456;service=agent;foo:bar=baz")
+		d, err := parseLegacy("did:a:123:456;service=agent;foo:bar=baz")
 		assert(t, nil, err)
 		assert(t, 2, len(d.Params))
 		assert(t, "service", d.Params[0].Name)
@@ -370,7 +438,7 @@ func TestParse(t *testing.T) {
 	// nolint: dupl
 	// test for params look similar to linter
 	t.Run("succeeds to extract path after generic param", func(t *testing.T) {
-		d, err := Parse("did:a:123:456;service==value/a/b")
+		d, err := parseLegacy("did:a:123:456;service==value/a/b")
 		assert(t, nil, err)
 		assert(t, 1, len(d.Params))
 		assert(t, "service=value", d.Params[0].String())
@@ -385,7 +453,7 @@ func TestParse(t *testing.T) {
 	// nolint: dupl
 	// test for params look similar to linter
 	t.Run("succeeds to extract path after generic param name and no value", func(t *testing.T) {
-		d, err := Parse("did:a:123:456;service=/a/b")
+		d, err := parseLegacy("did:a:123:456;service=/a/b")
 		assert(t, nil, err)
 		assert(t, 1, len(d.Params))
 		assert(t, "service", d.Params[0].String())
@@ -400,7 +468,7 @@ func TestParse(t *testing.T) {
 	// nolint: dupl
 	// test for params look similar to linter
 	t.Run("succeeds to extract query after generic param", func(t *testing.T) {
-		d, err := Parse("did:a:123:456;service=value?abc")
+		d, err := parseLegacy("did:a:123:456;service=value?abc")
 		assert(t, nil, err)
 		assert(t, 1, len(d.Params))
 		assert(t, "service=value", d.Params[0].String())
@@ -412,7 +480,7 @@ func TestParse(t *testing.T) {
 	// nolint: dupl
 	// test for params look similar to linter
 	t.Run("succeeds to extract fragment after generic param", func(t *testing.T) {
-		d, err := Parse("did:a:123:456;service=value#xyz")
+		d, err := parseLegacy("did:a:123:456;service=value#xyz")
 		assert(t, nil, err)
 		assert(t, 1, len(d.Params))
 		assert(t, "service=value", d.Params[0].String())
@@ -457,13 +525,27 @@ func TestParse(t *testing.T) {
 		}
 	})
 
-	t.Run("returns error if path is empty but there is a slash", func(t *testing.T) {
-		_, err := Parse("did:a:123:456/")
+	t.Run("succeeds if path is empty but there is a slash", func(t *testing.T) {
+		d, err := Parse("did:a:123:456/")
+		assert(t, nil, err)
+		assert(t, []string{""}, d.PathSegments)
+		assert(t, "did:a:123:456/", d.String())
+	})
+
+	t.Run("succeeds if first path segment is empty", func(t *testing.T) {
+		d, err := Parse("did:a:123:456//abc")
+		assert(t, nil, err)
+		assert(t, []string{"", "abc"}, d.PathSegments)
+		assert(t, "did:a:123:456//abc", d.String())
+	})
+
+	t.Run("returns error if path is empty but there is a slash with legacy grammar", func(t *testing.T) {
+		_, err := parseLegacy("did:a:123:456/")
 		assert(t, false, err == nil)
 	})
 
-	t.Run("returns error if first path segment is empty", func(t *testing.T) {
-		_, err := Parse("did:a:123:456//abc")
+	t.Run("returns error if first path segment is empty with legacy grammar", func(t *testing.T) {
+		_, err := parseLegacy("did:a:123:456//abc")
 		assert(t, false, err == nil)
 	})
 
@@ -499,6 +581,46 @@ func TestParse(t *testing.T) {
 		assert(t, "abc", d.Query)
 	})
 
+	t.Run("succeeds to extract fragment after path", func(t *testing.T) {
+		d, err := Parse("did:a:123/a/b#xyz")
+		assert(t, nil, err)
+		assert(t, "a/b", d.Path)
+		assert(t, "xyz", d.Fragment)
+	})
+
+	t.Run("terminates a path at a fragment in both grammars", func(t *testing.T) {
+		// the parser used to reject a `#` in a path, unless a query came first, as a character that is not allowed
+		tests := []struct {
+			input    string
+			path     string
+			fragment string
+		}{
+			{"did:a:123/path#frag", "path", "frag"},
+			{"did:a:123/a/b#key-1", "a/b", "key-1"},
+			{"did:a:123/a#", "a", ""},
+			{"did:a:123/a/b#k/../x?y", "a/b", "k/../x?y"},
+			{"did:a:123/a;b=c#d", "a;b=c", "d"},
+		}
+
+		for _, test := range tests {
+			for _, grammar := range []Grammar{GrammarCore10, GrammarLegacyCCG} {
+				d, err := ParseWithOptions(test.input, ParseOptions{Grammar: grammar})
+				assert(t, nil, err, "Input: %s Grammar: %d", test.input, grammar)
+				assert(t, test.path, d.Path, "Input: %s Grammar: %d", test.input, grammar)
+				assert(t, test.fragment, d.Fragment, "Input: %s Grammar: %d", test.input, grammar)
+			}
+		}
+
+		d, err := Parse("did:a:123/#f")
+		assert(t, nil, err)
+		assert(t, []string{""}, d.PathSegments)
+		assert(t, "f", d.Fragment)
+		assert(t, "did:a:123/#f", d.String())
+
+		_, err = Parse("did:a:123/a#b#c")
+		assert(t, true, errors.Is(err, ErrInvalidCharacter))
+	})
+
 	t.Run("succeeds to extract fragment after query", func(t *testing.T) {
 		d, err := Parse("did:a:123?abc#xyz")
 		assert(t, nil, err)
@@ -565,17 +687,139 @@ func TestParse(t *testing.T) {
 	})
 }
 
+func TestParseBytes(t *testing.T) {
+	input := []byte("did:a:123/b#c")
+	d, err := ParseBytes(input)
+	assert(t, nil, err)
+
+	// the DID must not change when the input does
+	copy(input, "did:x:yyy/z#w")
+	assert(t, "did:a:123/b#c", d.String())
+
+	_, err = ParseBytes([]byte("did:a:"))
+	assert(t, false, err == nil)
+}
+
+func TestParseInto(t *testing.T) {
+	t.Run("parses into the DID", func(t *testing.T) {
+		d := &DID{Method: "old", ID: "old", Query: "old", Fragment: "old"}
+		err := ParseInto(d, "did:a:123:456/b/c")
+		assert(t, nil, err)
+
+		expected, err := Parse("did:a:123:456/b/c")
+		assert(t, nil, err)
+		assert(t, expected, d)
+	})
+
+	t.Run("resets the DID on error", func(t *testing.T) {
+		d := &DID{Method: "old", ID: "old"}
+		err := ParseInto(d, "did:a:1^3")
+		assert(t, false, err == nil)
+		assert(t, "", d.Method)
+		assert(t, "", d.ID)
+		assert(t, 0, len(d.IDStrings))
+	})
+
+	t.Run("sets ID and Path to substrings of the input", func(t *testing.T) {
+		d := &DID{}
+		err := ParseInto(d, "did:a:123::456/b//c")
+		assert(t, nil, err)
+		assert(t, "123::456", d.ID)
+		assert(t, []string{"123", "", "456"}, d.IDStrings)
+		assert(t, "b//c", d.Path)
+		assert(t, []string{"b", "", "c"}, d.PathSegments)
+	})
+
+	t.Run("does not allocate when the DID is reused", func(t *testing.T) {
+		d := &DID{}
+		allocs := testing.AllocsPerRun(100, func() {
+			_ = ParseInto(d, "did:a:123:456/b/c?d=e#f")
+		})
+		assert(t, float64(0), allocs)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	inputs := []string{
+		"did:a:123",
+		"did:a:123:456/b/c?d=e#f",
+		"did:a::123%20/",
+		"did:a:123:",
+		"did:a:123;a=b",
+		"did:a:1^3",
+		"dxd:a:123",
+		"",
+	}
+
+	for _, input := range inputs {
+		_, expected := Parse(input)
+		assert(t, expected, Validate(input), "Input: %s", input)
+	}
+
+	t.Run("does not allocate", func(t *testing.T) {
+		allocs := testing.AllocsPerRun(100, func() {
+			_ = Validate("did:a:123:456/b/c?d=e#f")
+		})
+		assert(t, float64(0), allocs)
+	})
+}
+
+func TestParseWithOptionsLimits(t *testing.T) {
+	tests := []struct {
+		input  string
+		opts   ParseOptions
+		offset int
+		err    error
+	}{
+		{"did:a:123", ParseOptions{MaxLength: 9}, 0, nil},
+		{"did:a:1234", ParseOptions{MaxLength: 9}, 9, ErrLimitExceeded},
+		{"did:a:1:2:3", ParseOptions{MaxIDStrings: 3}, 0, nil},
+		{"did:a:1:2:3:4", ParseOptions{MaxIDStrings: 3}, 12, ErrLimitExceeded},
+		{"did:a:1/b/c", ParseOptions{MaxPathSegments: 2}, 0, nil},
+		{"did:a:1/b/c/", ParseOptions{MaxPathSegments: 2}, 12, ErrLimitExceeded},
+		{"did:a:1;b;c", ParseOptions{Grammar: GrammarLegacyCCG, MaxParams: 2}, 0, nil},
+		{"did:a:1;b;c;d=e", ParseOptions{Grammar: GrammarLegacyCCG, MaxParams: 2}, 12, ErrLimitExceeded},
+		{"did:key:123", ParseOptions{AllowedMethods: []string{"web", "key"}}, 0, nil},
+		{"did:kex:123", ParseOptions{AllowedMethods: []string{"web", "key"}}, 4, ErrMethodNotAllowed},
+		{"did:a:1:2:3:4/b/c/d", ParseOptions{MaxIDStrings: -1, MaxPathSegments: 0}, 0, nil},
+	}
+
+	for _, test := range tests {
+		_, err := ParseWithOptions(test.input, test.opts)
+		if test.err == nil {
+			assert(t, nil, err, "Input: %s", test.input)
+			continue
+		}
+
+		var pe *ParseError
+		assert(t, true, errors.As(err, &pe), "Input: %s", test.input)
+		assert(t, true, errors.Is(err, test.err), "Input: %s", test.input)
+		assert(t, test.offset, pe.Offset, "Input: %s", test.input)
+	}
+}
+
 func Test_errorf(t *testing.T) {
-	p := &parser{}
-	p.errorf(10, "%s,%s", "a", "b")
+	p := &parser{input: "did:a:123"}
+	p.errorf(10, ComponentPath, "pchar", ErrInvalidCharacter, "%s,%s", "a", "b")
 
 	if p.currentIndex != 10 {
 		t.Errorf("did not set currentIndex")
 	}
 
+	pe, ok := p.err.(*ParseError)
+	if !ok {
+		t.Fatalf("err is a %T expected: *ParseError", p.err)
+	}
+
+	assert(t, "did:a:123", pe.Input)
+	assert(t, 10, pe.Offset)
+	assert(t, ComponentPath, pe.Component)
+	assert(t, "pchar", pe.Rule)
+	assert(t, ErrInvalidCharacter, pe.Err)
+
 	e := p.err.Error()
-	if e != "a,b" {
-		t.Errorf("err message is: '%s' expected: 'a,b'", e)
+	if e != "did: a,b at offset 10 (pchar)" {
+		t.Errorf("err message is: '%s' expected: 'did: a,b at offset 10 (pchar)'", e)
 	}
 }
 
@@ -602,12 +846,12 @@ func Test_isNotValidIDChar(t *testing.T) {
 		'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm',
 		'n', 'o', 'p', 'q', 'r', 's', 't', 'u', 'v', 'w', 'x', 'y', 'z',
 		'0', '1', '2', '3', '4', '5', '6', '7', '8', '9',
-		'.', '-'}
+		'.', '-', '_'}
 	for _, c := range a {
 		assert(t, false, isNotValidIDChar(c), "Input: '%c'", c)
 	}
 
-	a = []byte{'%', '^', '#', ' ', '_', '~', '!', '$', '&', '\'', '(', ')', '*', '+', ',', ';', '=', ':', '@', '/', '?'}
+	a = []byte{'%', '^', '#', ' ', '~', '!', '$', '&', '\'', '(', ')', '*', '+', ',', ';', '=', ':', '@', '/', '?'}
 	for _, c := range a {
 		assert(t, true, isNotValidIDChar(c), "Input: '%c'", c)
 	}
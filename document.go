@@ -0,0 +1,247 @@
+package did
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ContextV1 is the JSON-LD context of DID Core, it must be the first context of a DID document
+// https://www.w3.org/TR/did-core/#json-ld
+const ContextV1 = "https://www.w3.org/ns/did/v1"
+
+// The names of the verification relationships that are defined by DID Core
+// https://www.w3.org/TR/did-core/#verification-relationships
+const (
+	RelationshipAuthentication       = "authentication"
+	RelationshipAssertionMethod      = "assertionMethod"
+	RelationshipKeyAgreement         = "keyAgreement"
+	RelationshipCapabilityInvocation = "capabilityInvocation"
+	RelationshipCapabilityDelegation = "capabilityDelegation"
+)
+
+// Relationships are the names of the verification relationships in the order in which they are defined by DID Core
+var Relationships = []string{
+	RelationshipAuthentication,
+	RelationshipAssertionMethod,
+	RelationshipKeyAgreement,
+	RelationshipCapabilityInvocation,
+	RelationshipCapabilityDelegation,
+}
+
+var (
+	// ErrUnknownRelationship is returned when the name of a verification relationship is not defined by DID Core
+	ErrUnknownRelationship = errors.New("unknown verification relationship")
+
+	// ErrVerificationMethodNotFound is returned when a verification method that is referred to is not
+	// in a DID document
+	ErrVerificationMethodNotFound = errors.New("verification method not found")
+)
+
+// A Document is a DID document, the set of data that describes the subject of a DID
+// https://www.w3.org/TR/did-core/#core-properties
+type Document struct {
+	// Context is the JSON-LD context of the document, each context is a URL string
+	// or an embedded context, which is a map[string]interface{}
+	// https://www.w3.org/TR/did-core/#json-ld
+	Context []interface{}
+
+	// ID is the DID of the subject of the document
+	// https://www.w3.org/TR/did-core/#did-subject
+	ID *DID
+
+	// Controller are the DIDs of the entities that are authorized to make changes to the document,
+	// it is a single DID or a set of DIDs
+	// https://www.w3.org/TR/did-core/#did-controller
+	Controller []*DID
+
+	// AlsoKnownAs are other URIs of the subject
+	// https://www.w3.org/TR/did-core/#also-known-as
+	AlsoKnownAs []string
+
+	// VerificationMethod are the verification methods of the document
+	// https://www.w3.org/TR/did-core/#verification-methods
+	VerificationMethod []VerificationMethod
+
+	// The verification relationships, each entry embeds a verification method or refers to one
+	// https://www.w3.org/TR/did-core/#verification-relationships
+	Authentication       []RelationshipEntry
+	AssertionMethod      []RelationshipEntry
+	KeyAgreement         []RelationshipEntry
+	CapabilityInvocation []RelationshipEntry
+	CapabilityDelegation []RelationshipEntry
+
+	// Service are the services of the subject, ex- a messaging endpoint
+	// https://www.w3.org/TR/did-core/#services
+	Service []Service
+
+	// Properties holds the properties of the document that are not defined by DID Core, by name
+	Properties map[string]interface{}
+}
+
+// A VerificationMethod is a public key, or another way to verify a proof, of the subject of a DID document
+// https://www.w3.org/TR/did-core/#verification-methods
+type VerificationMethod struct {
+	// ID is the DID URL of the verification method, it may be a relative DID URL reference
+	// that is resolved against the ID of the document, ex- #key-1
+	ID *DID
+
+	// Type is the type of the verification method, ex- Ed25519VerificationKey2020
+	Type string
+
+	// Controller is the DID of the entity that is authorized to use the verification method
+	Controller *DID
+
+	// PublicKeyJwk is the public key as a JSON Web Key
+	// https://www.w3.org/TR/did-core/#dfn-publickeyjwk
+	PublicKeyJwk map[string]interface{}
+
+	// PublicKeyMultibase is the public key as a Multibase encoded string
+	// https://www.w3.org/TR/did-core/#dfn-publickeymultibase
+	PublicKeyMultibase string
+
+	// Properties holds the properties of the verification method that are not defined by DID Core, by name
+	Properties map[string]interface{}
+}
+
+// A RelationshipEntry is an entry of a verification relationship, it either embeds a verification method,
+// which is only authorized for that relationship, or refers to a verification method of the document
+// https://www.w3.org/TR/did-core/#referring-to-verification-methods
+type RelationshipEntry struct {
+	// Reference is the DID URL of the verification method that the entry refers to,
+	// it may be a relative DID URL reference
+	Reference *DID
+
+	// Method is the embedded verification method, it is only set if Reference is nil
+	Method *VerificationMethod
+}
+
+// A Service is a way to communicate with the subject of a DID document
+// https://www.w3.org/TR/did-core/#services
+type Service struct {
+	// ID is the URI of the service, it may be a relative DID URL reference, ex- #messaging
+	ID string
+
+	// Type is the type of the service, it is a single string or a set of strings
+	Type []string
+
+	// ServiceEndpoint are the endpoints of the service, each endpoint is a URI string or a map[string]interface{},
+	// the endpoint is a single value or a set of values
+	// https://www.w3.org/TR/did-core/#dfn-serviceendpoint
+	ServiceEndpoint []interface{}
+
+	// Properties holds the properties of the service that are not defined by DID Core, by name
+	Properties map[string]interface{}
+}
+
+// Relationship returns the entries of a verification relationship by name, ex- RelationshipAuthentication.
+// It returns an error that wraps ErrUnknownRelationship if the name is not defined by DID Core.
+func (doc *Document) Relationship(name string) ([]RelationshipEntry, error) {
+	entries := doc.relationship(name)
+	if entries == nil {
+		return nil, fmt.Errorf("did: %q: %w", name, ErrUnknownRelationship)
+	}
+	return *entries, nil
+}
+
+// relationship returns a pointer to the field of a verification relationship, or nil if the name is unknown
+func (doc *Document) relationship(name string) *[]RelationshipEntry {
+	switch name {
+	case RelationshipAuthentication:
+		return &doc.Authentication
+	case RelationshipAssertionMethod:
+		return &doc.AssertionMethod
+	case RelationshipKeyAgreement:
+		return &doc.KeyAgreement
+	case RelationshipCapabilityInvocation:
+		return &doc.CapabilityInvocation
+	case RelationshipCapabilityDelegation:
+		return &doc.CapabilityDelegation
+	default:
+		return nil
+	}
+}
+
+// ResolveReference resolves a DID URL reference against the ID of the document,
+// ref is returned as is if the document does not have an ID
+func (doc *Document) ResolveReference(ref *DID) *DID {
+	if doc.ID == nil {
+		return ref
+	}
+	return doc.ID.ResolveReference(ref)
+}
+
+// VerificationMethodByID returns the verification method of the document whose ID is id, or nil if there is none.
+// Relative DID URL references are resolved against the ID of the document before they are compared, so
+// #key-1 finds the method did:example:123#key-1 of did:example:123. The methods in VerificationMethod are
+// searched first, followed by the methods that are embedded in the verification relationships.
+func (doc *Document) VerificationMethodByID(id *DID) *VerificationMethod {
+	target := doc.ResolveReference(id)
+
+	for i := range doc.VerificationMethod {
+		if method := &doc.VerificationMethod[i]; method.ID != nil && Equal(doc.ResolveReference(method.ID), target) {
+			return method
+		}
+	}
+
+	for _, name := range Relationships {
+		for _, entry := range *doc.relationship(name) {
+			if method := entry.Method; method != nil && method.ID != nil &&
+				Equal(doc.ResolveReference(method.ID), target) {
+				return method
+			}
+		}
+	}
+
+	return nil
+}
+
+// VerificationMethods returns the verification methods of a verification relationship by name, in order,
+// with the references replaced by the methods they refer to. It returns an error that wraps
+// ErrVerificationMethodNotFound if a reference does not refer to a method of the document.
+func (doc *Document) VerificationMethods(name string) ([]*VerificationMethod, error) {
+	entries, err := doc.Relationship(name)
+	if err != nil {
+		return nil, err
+	}
+
+	methods := make([]*VerificationMethod, 0, len(entries))
+	for _, entry := range entries {
+		method := entry.Method
+		if entry.Reference != nil {
+			method = doc.VerificationMethodByID(entry.Reference)
+			if method == nil {
+				return nil, fmt.Errorf("did: %s refers to %s: %w", name, entry.Reference, ErrVerificationMethodNotFound)
+			}
+		}
+		if method != nil {
+			methods = append(methods, method)
+		}
+	}
+
+	return methods, nil
+}
+
+// ServiceByID returns the service of the document whose ID is id, or nil if there is none.
+// If both IDs are DID URLs or relative DID URL references, they are resolved against the ID of the document
+// and compared as DIDs, otherwise they are compared as strings.
+func (doc *Document) ServiceByID(id string) *Service {
+	target, err := ParseReference(id)
+	if err == nil {
+		target = doc.ResolveReference(target)
+	}
+
+	for i := range doc.Service {
+		service := &doc.Service[i]
+		if service.ID == id {
+			return service
+		}
+		if target == nil {
+			continue
+		}
+		if ref, err := ParseReference(service.ID); err == nil && Equal(doc.ResolveReference(ref), target) {
+			return service
+		}
+	}
+
+	return nil
+}
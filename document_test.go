@@ -0,0 +1,122 @@
+package did
+
+import (
+	"errors"
+	"testing"
+)
+
+// testDocument returns a DID document with referenced and embedded verification methods
+func testDocument(t *testing.T) *Document {
+	t.Helper()
+	id := mustParse(t, "did:example:123")
+	key1, err := ParseReference("#key-1")
+	assert(t, nil, err)
+
+	return &Document{
+		Context: []interface{}{ContextV1},
+		ID:      id,
+		VerificationMethod: []VerificationMethod{
+			{ID: key1, Type: "Ed25519VerificationKey2020", Controller: id, PublicKeyMultibase: "z6Mk"},
+			{ID: mustParse(t, "did:example:123#key-2"), Type: "JsonWebKey2020", Controller: id,
+				PublicKeyJwk: map[string]interface{}{"kty": "OKP", "crv": "Ed25519", "x": "VCpo"}},
+		},
+		Authentication: []RelationshipEntry{
+			{Reference: mustParse(t, "did:example:123#key-1")},
+			{Method: &VerificationMethod{ID: mustParse(t, "did:example:123#auth"), Type: "Multikey",
+				Controller: id, PublicKeyMultibase: "z6Mkauth"}},
+		},
+		AssertionMethod: []RelationshipEntry{{Reference: mustParse(t, "did:example:123#key-2")}},
+		KeyAgreement:    []RelationshipEntry{{Reference: mustParse(t, "did:example:123#missing")}},
+		Service: []Service{
+			{ID: "#messaging", Type: []string{"DIDCommMessaging"}, ServiceEndpoint: []interface{}{"https://example.com"}},
+			{ID: "https://example.com/linked", Type: []string{"LinkedDomains"},
+				ServiceEndpoint: []interface{}{map[string]interface{}{"origins": []interface{}{"https://example.com"}}}},
+		},
+	}
+}
+
+func TestDocumentRelationship(t *testing.T) {
+	doc := testDocument(t)
+
+	for _, name := range Relationships {
+		entries, err := doc.Relationship(name)
+		assert(t, nil, err, "Relationship: %s", name)
+		assert(t, *doc.relationship(name), entries, "Relationship: %s", name)
+	}
+
+	entries, err := doc.Relationship(RelationshipAuthentication)
+	assert(t, nil, err)
+	assert(t, 2, len(entries))
+
+	_, err = doc.Relationship("publicKey")
+	assert(t, true, errors.Is(err, ErrUnknownRelationship))
+}
+
+func TestDocumentVerificationMethodByID(t *testing.T) {
+	doc := testDocument(t)
+
+	tests := []struct {
+		id       string
+		expected *VerificationMethod
+	}{
+		{"did:example:123#key-1", &doc.VerificationMethod[0]},
+		{"#key-1", &doc.VerificationMethod[0]},
+		{"did:example:123#key%2D1", &doc.VerificationMethod[0]},
+		{"#key-2", &doc.VerificationMethod[1]},
+		{"did:example:123#auth", doc.Authentication[1].Method},
+		{"did:example:456#key-1", nil},
+		{"#missing", nil},
+	}
+
+	for _, test := range tests {
+		id, err := ParseReference(test.id)
+		assert(t, nil, err, "ID: %s", test.id)
+		assert(t, true, doc.VerificationMethodByID(id) == test.expected, "ID: %s", test.id)
+	}
+}
+
+func TestDocumentVerificationMethods(t *testing.T) {
+	doc := testDocument(t)
+
+	methods, err := doc.VerificationMethods(RelationshipAuthentication)
+	assert(t, nil, err)
+	assert(t, []*VerificationMethod{&doc.VerificationMethod[0], doc.Authentication[1].Method}, methods)
+
+	methods, err = doc.VerificationMethods(RelationshipAssertionMethod)
+	assert(t, nil, err)
+	assert(t, []*VerificationMethod{&doc.VerificationMethod[1]}, methods)
+
+	methods, err = doc.VerificationMethods(RelationshipCapabilityInvocation)
+	assert(t, nil, err)
+	assert(t, []*VerificationMethod{}, methods)
+
+	_, err = doc.VerificationMethods(RelationshipKeyAgreement)
+	assert(t, true, errors.Is(err, ErrVerificationMethodNotFound))
+
+	_, err = doc.VerificationMethods("publicKey")
+	assert(t, true, errors.Is(err, ErrUnknownRelationship))
+}
+
+func TestDocumentServiceByID(t *testing.T) {
+	doc := testDocument(t)
+
+	tests := []struct {
+		id       string
+		expected *Service
+	}{
+		{"#messaging", &doc.Service[0]},
+		{"did:example:123#messaging", &doc.Service[0]},
+		{"https://example.com/linked", &doc.Service[1]},
+		{"did:example:456#messaging", nil},
+		{"https://example.com/other", nil},
+	}
+
+	for _, test := range tests {
+		assert(t, true, doc.ServiceByID(test.id) == test.expected, "ID: %s", test.id)
+	}
+
+	// without an ID, relative references are compared as they are
+	doc.ID = nil
+	assert(t, true, doc.ServiceByID("#messaging") == &doc.Service[0])
+	assert(t, true, doc.ServiceByID("did:example:123#messaging") == nil)
+}
@@ -0,0 +1,108 @@
+package did
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// DID can be used as a field in JSON documents, config files and SQL rows, it is encoded as a string
+var (
+	_ encoding.TextMarshaler     = (*DID)(nil)
+	_ encoding.TextUnmarshaler   = (*DID)(nil)
+	_ encoding.BinaryMarshaler   = (*DID)(nil)
+	_ encoding.BinaryUnmarshaler = (*DID)(nil)
+	_ json.Marshaler             = (*DID)(nil)
+	_ json.Unmarshaler           = (*DID)(nil)
+	_ sql.Scanner                = (*DID)(nil)
+	_ driver.Valuer              = (*DID)(nil)
+)
+
+// errCannotEncode is returned when a DID does not have enough components to be encoded as a string
+var errCannotEncode = errors.New("did: cannot encode a DID without a Method and an ID")
+
+// MarshalText implements the encoding.TextMarshaler interface, a DID is encoded as its String
+func (d *DID) MarshalText() ([]byte, error) {
+	s := d.String()
+	if s == "" {
+		return nil, errCannotEncode
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface, the text is parsed with Parse
+// and the returned error is the *ParseError if the text is not a valid DID
+func (d *DID) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*d = *parsed
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface, it is the same as MarshalText
+func (d *DID) MarshalBinary() ([]byte, error) {
+	return d.MarshalText()
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface, it is the same as UnmarshalText
+func (d *DID) UnmarshalBinary(data []byte) error {
+	return d.UnmarshalText(data)
+}
+
+// MarshalJSON implements the json.Marshaler interface, a DID is encoded as a JSON string
+func (d *DID) MarshalJSON() ([]byte, error) {
+	text, err := d.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, a DID must be encoded as a JSON string,
+// which is parsed with Parse. By convention, a JSON null leaves the DID unchanged.
+func (d *DID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("did: a DID must be a JSON string: %w", err)
+	}
+
+	return d.UnmarshalText([]byte(s))
+}
+
+// Scan implements the sql.Scanner interface, a DID can be scanned from a string or a []byte column,
+// a NULL column resets the DID to its zero value.
+func (d *DID) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case string:
+		return d.UnmarshalText([]byte(src))
+	case []byte:
+		return d.UnmarshalText(src)
+	case nil:
+		*d = DID{}
+		return nil
+	default:
+		return fmt.Errorf("did: cannot scan a %T into a DID", src)
+	}
+}
+
+// Value implements the driver.Valuer interface, a DID is stored as a string and a nil DID as NULL
+func (d *DID) Value() (driver.Value, error) {
+	if d == nil {
+		return nil, nil
+	}
+
+	text, err := d.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
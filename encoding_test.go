@@ -0,0 +1,120 @@
+package did
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMarshalText(t *testing.T) {
+	t.Run("encodes a DID as its string", func(t *testing.T) {
+		text, err := (&DID{Method: "example", ID: "123", Fragment: "key-1"}).MarshalText()
+		assert(t, nil, err)
+		assert(t, "did:example:123#key-1", string(text))
+	})
+
+	t.Run("returns error if DID has no method", func(t *testing.T) {
+		_, err := (&DID{ID: "123"}).MarshalText()
+		assert(t, false, err == nil)
+	})
+
+	t.Run("decodes a DID", func(t *testing.T) {
+		var d DID
+		err := d.UnmarshalText([]byte("did:example:123/a"))
+		assert(t, nil, err)
+		assert(t, "example", d.Method)
+		assert(t, "123", d.ID)
+		assert(t, "a", d.Path)
+	})
+
+	t.Run("returns the parse error if text is not a DID", func(t *testing.T) {
+		var d DID
+		err := d.UnmarshalText([]byte("did:example:1^3"))
+
+		var pe *ParseError
+		assert(t, true, errors.As(err, &pe))
+		assert(t, 13, pe.Offset)
+	})
+}
+
+func TestMarshalBinary(t *testing.T) {
+	data, err := (&DID{Method: "example", ID: "123"}).MarshalBinary()
+	assert(t, nil, err)
+
+	var d DID
+	assert(t, nil, d.UnmarshalBinary(data))
+	assert(t, "did:example:123", d.String())
+}
+
+func TestMarshalJSON(t *testing.T) {
+	type record struct {
+		Subject  DID  `json:"subject"`
+		Issuer   *DID `json:"issuer"`
+		Verifier *DID `json:"verifier,omitempty"`
+	}
+
+	t.Run("round trips a struct with DID fields", func(t *testing.T) {
+		data, err := json.Marshal(&record{
+			Subject: DID{Method: "example", ID: "123"},
+			Issuer:  &DID{Method: "example", ID: "456", Fragment: "key-1"},
+		})
+		assert(t, nil, err)
+		assert(t, `{"subject":"did:example:123","issuer":"did:example:456#key-1"}`, string(data))
+
+		var r record
+		err = json.Unmarshal(data, &r)
+		assert(t, nil, err)
+		assert(t, "did:example:123", r.Subject.String())
+		assert(t, "did:example:456#key-1", r.Issuer.String())
+		assert(t, true, r.Verifier == nil)
+	})
+
+	t.Run("leaves a DID unchanged on null", func(t *testing.T) {
+		var r record
+		err := json.Unmarshal([]byte(`{"subject":"did:example:123","issuer":null}`), &r)
+		assert(t, nil, err)
+		assert(t, true, r.Issuer == nil)
+	})
+
+	t.Run("returns error if value is not a string", func(t *testing.T) {
+		var r record
+		err := json.Unmarshal([]byte(`{"subject":123}`), &r)
+		assert(t, false, err == nil)
+	})
+
+	t.Run("returns the parse error if value is not a DID", func(t *testing.T) {
+		var r record
+		err := json.Unmarshal([]byte(`{"subject":"did:example:"}`), &r)
+		assert(t, true, errors.Is(err, ErrEmptyIDString))
+	})
+}
+
+func TestScan(t *testing.T) {
+	var d DID
+	assert(t, nil, d.Scan("did:example:123"))
+	assert(t, "did:example:123", d.String())
+
+	assert(t, nil, d.Scan([]byte("did:example:456")))
+	assert(t, "did:example:456", d.String())
+
+	assert(t, nil, d.Scan(nil))
+	assert(t, DID{}, d)
+
+	assert(t, false, d.Scan(123) == nil)
+	assert(t, true, errors.Is(d.Scan("did:example:4 6"), ErrInvalidCharacter))
+}
+
+func TestValue(t *testing.T) {
+	v, err := (&DID{Method: "example", ID: "123"}).Value()
+	assert(t, nil, err)
+	assert(t, driver.Value("did:example:123"), v)
+
+	var d *DID
+	v, err = d.Value()
+	assert(t, nil, err)
+	assert(t, nil, v)
+
+	_, err = (&DID{}).Value()
+	assert(t, false, err == nil)
+}
@@ -0,0 +1,123 @@
+package did
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors wrapped by a ParseError, they can be tested for with errors.Is
+var (
+	// ErrTooShort is returned when the input is shorter than the shortest possible DID
+	ErrTooShort = errors.New("input is too short")
+
+	// ErrInvalidScheme is returned when the input does not begin with the `did:` prefix
+	ErrInvalidScheme = errors.New("input does not begin with 'did:' prefix")
+
+	// ErrMissingMethod is returned when the method name is empty or is not followed by a `:`
+	ErrMissingMethod = errors.New("method name is missing")
+
+	// ErrEmptyIDString is returned when an idstring of the method-specific-id is empty
+	ErrEmptyIDString = errors.New("idstring is empty")
+
+	// ErrEmptyParamName is returned when a param has no name
+	ErrEmptyParamName = errors.New("param name is empty")
+
+	// ErrEmptyPathSegment is returned when a path segment that must not be empty is empty
+	ErrEmptyPathSegment = errors.New("path segment is empty")
+
+	// ErrInvalidCharacter is returned when a character is not allowed in the component being parsed
+	ErrInvalidCharacter = errors.New("character is not allowed")
+
+	// ErrInvalidPercentEncoding is returned when a `%` is not followed by 2 hex digits
+	ErrInvalidPercentEncoding = errors.New("invalid percent encoding")
+
+	// ErrInvalidReference is returned by ParseReference when the input is a URI reference that
+	// cannot be a relative DID URL, for example a network-path reference like `//host/path`
+	ErrInvalidReference = errors.New("not a relative DID URL")
+
+	// ErrLimitExceeded is returned when the input exceeds one of the limits set in ParseOptions
+	ErrLimitExceeded = errors.New("limit exceeded")
+
+	// ErrMethodNotAllowed is returned when the method is not one of the AllowedMethods set in ParseOptions
+	ErrMethodNotAllowed = errors.New("method is not allowed")
+)
+
+// Component identifies a part of a DID or a DID URL
+type Component int
+
+// The components of a DID URL, in the order in which they appear in the input
+const (
+	ComponentScheme Component = iota
+	ComponentMethod
+	ComponentMethodSpecificID
+	ComponentParam
+	ComponentPath
+	ComponentQuery
+	ComponentFragment
+)
+
+// String returns the name of a Component
+func (c Component) String() string {
+	switch c {
+	case ComponentScheme:
+		return "scheme"
+	case ComponentMethod:
+		return "method"
+	case ComponentMethodSpecificID:
+		return "method-specific-id"
+	case ComponentParam:
+		return "param"
+	case ComponentPath:
+		return "path"
+	case ComponentQuery:
+		return "query"
+	case ComponentFragment:
+		return "fragment"
+	default:
+		return fmt.Sprintf("Component(%d)", int(c))
+	}
+}
+
+// A ParseError describes why an input could not be parsed and where in the input the problem was found
+type ParseError struct {
+	// Input is the string that was being parsed
+	Input string
+
+	// Offset is the byte offset in Input at which the parser stopped
+	Offset int
+
+	// Component is the part of the DID that was being parsed
+	Component Component
+
+	// Rule is the name of the rule in the DID grammar (did.abnf) that was violated
+	Rule string
+
+	// Err is one of the sentinel errors declared in this package, for example ErrInvalidCharacter
+	Err error
+
+	// a human readable description of the problem
+	message string
+}
+
+// Error returns a description of the error that includes its offset
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("did: %s at offset %d (%s)", e.message, e.Offset, e.Rule)
+}
+
+// Unwrap returns the sentinel error wrapped by a ParseError
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// newParseError returns a *ParseError for the given input
+func newParseError(input string, offset int, component Component, rule string, err error,
+	format string, args ...interface{}) *ParseError {
+	return &ParseError{
+		Input:     input,
+		Offset:    offset,
+		Component: component,
+		Rule:      rule,
+		Err:       err,
+		message:   fmt.Sprintf(format, args...),
+	}
+}
@@ -0,0 +1,60 @@
+package did
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		input     string
+		offset    int
+		component Component
+		rule      string
+		err       error
+		grammar   Grammar
+	}{
+		{"did:a", 5, ComponentScheme, "did", ErrTooShort, GrammarCore10},
+		{"dxd:a:123", 1, ComponentScheme, "did", ErrInvalidScheme, GrammarCore10},
+		{"did:aaaaaaaaaaa", 15, ComponentMethod, "did", ErrMissingMethod, GrammarCore10},
+		{"did::aaaaaaaaaaa", 4, ComponentMethod, "method-name", ErrMissingMethod, GrammarCore10},
+		{"did:aA:1", 5, ComponentMethod, "method-char", ErrInvalidCharacter, GrammarCore10},
+		{"did:a:1&&111", 7, ComponentMethodSpecificID, "idchar", ErrInvalidCharacter, GrammarCore10},
+		{"did:a:123:", 10, ComponentMethodSpecificID, "method-specific-id", ErrEmptyIDString, GrammarCore10},
+		{"did:a::123", 6, ComponentMethodSpecificID, "method-specific-id", ErrEmptyIDString, GrammarLegacyCCG},
+		{"did:a:1%2", 7, ComponentMethodSpecificID, "pct-encoded", ErrInvalidPercentEncoding, GrammarCore10},
+		{"did:a:123;", 9, ComponentMethodSpecificID, "idchar", ErrInvalidCharacter, GrammarCore10},
+		{"did:a:123;", 10, ComponentParam, "param-name", ErrEmptyParamName, GrammarLegacyCCG},
+		{"did:a:123;ser%2ge", 13, ComponentParam, "pct-encoded", ErrInvalidPercentEncoding, GrammarLegacyCCG},
+		{"did:a:123;serv&ce", 14, ComponentParam, "param-char", ErrInvalidCharacter, GrammarLegacyCCG},
+		{"did:a:123//abc", 10, ComponentPath, "segment-nz", ErrEmptyPathSegment, GrammarLegacyCCG},
+		{"did:a:123/ssss^sss", 14, ComponentPath, "pchar", ErrInvalidCharacter, GrammarCore10},
+		{"did:a:123/%A!", 10, ComponentPath, "pct-encoded", ErrInvalidPercentEncoding, GrammarCore10},
+		{"did:a:123?ab^c", 12, ComponentQuery, "query", ErrInvalidCharacter, GrammarCore10},
+		{"did:a:123#ab%", 12, ComponentFragment, "pct-encoded", ErrInvalidPercentEncoding, GrammarCore10},
+		{"did:a:123#ab c", 12, ComponentFragment, "fragment", ErrInvalidCharacter, GrammarCore10},
+	}
+
+	for _, test := range tests {
+		_, err := ParseWithOptions(test.input, ParseOptions{Grammar: test.grammar})
+
+		var pe *ParseError
+		assert(t, true, errors.As(err, &pe), "Input: %s", test.input)
+		assert(t, test.input, pe.Input, "Input: %s", test.input)
+		assert(t, test.offset, pe.Offset, "Input: %s", test.input)
+		assert(t, test.component, pe.Component, "Input: %s", test.input)
+		assert(t, test.rule, pe.Rule, "Input: %s", test.input)
+		assert(t, true, errors.Is(err, test.err), "Input: %s", test.input)
+	}
+}
+
+func TestComponentString(t *testing.T) {
+	assert(t, "scheme", ComponentScheme.String())
+	assert(t, "method", ComponentMethod.String())
+	assert(t, "method-specific-id", ComponentMethodSpecificID.String())
+	assert(t, "param", ComponentParam.String())
+	assert(t, "path", ComponentPath.String())
+	assert(t, "query", ComponentQuery.String())
+	assert(t, "fragment", ComponentFragment.String())
+	assert(t, "Component(42)", Component(42).String())
+}
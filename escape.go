@@ -0,0 +1,156 @@
+package did
+
+import (
+	"strings"
+)
+
+const upperhex = "0123456789ABCDEF"
+
+// EscapeIDString percent encodes s so it can be used as an idstring of a method-specific-id,
+// every byte that is not an idchar is encoded, including `:` which separates idstrings
+// from the grammar:
+//   idchar = ALPHA / DIGIT / "." / "-" / "_" / pct-encoded
+func EscapeIDString(s string) string {
+	return escape(s, isNotValidIDChar)
+}
+
+// EscapePathSegment percent encodes s so it can be used as a segment of a DID path,
+// every byte that is not a pchar is encoded, including `/` which separates segments
+// from the grammar:
+//   segment = *pchar
+//   pchar   = unreserved / pct-encoded / sub-delims / ":" / "@"
+func EscapePathSegment(s string) string {
+	return escape(s, isNotValidPathChar)
+}
+
+// EscapeQueryComponent percent encodes s so it can be used as the name or the value of a name=value pair
+// of a DID query, every byte that is not allowed in a query is encoded, and so are `&` and `=` which separate
+// the pairs, and `+` which HTML forms decode as a space
+// from the grammar:
+//   query = *( pchar / "/" / "?" )
+func EscapeQueryComponent(s string) string {
+	return escape(s, isNotValidQueryComponentChar)
+}
+
+// EscapeFragment percent encodes s so it can be used as a DID fragment,
+// every byte that is not allowed in a fragment is encoded
+// from the grammar:
+//   fragment = *( pchar / "/" / "?" )
+func EscapeFragment(s string) string {
+	return escape(s, isNotValidQueryOrFragmentChar)
+}
+
+// UnescapeIDString decodes an idstring that was encoded by EscapeIDString, it returns a *ParseError
+// if s contains a byte that is not an idchar, or a `%` that is not followed by 2 hex digits
+func UnescapeIDString(s string) (string, error) {
+	return checkAndUnescape(s, ComponentMethodSpecificID, "idchar", isNotValidIDChar)
+}
+
+// UnescapePathSegment decodes a path segment that was encoded by EscapePathSegment, it returns a *ParseError
+// if s contains a byte that is not a pchar, or a `%` that is not followed by 2 hex digits
+func UnescapePathSegment(s string) (string, error) {
+	return checkAndUnescape(s, ComponentPath, "pchar", isNotValidPathChar)
+}
+
+// UnescapeQueryComponent decodes the name or the value of a name=value pair of a DID query that was encoded
+// by EscapeQueryComponent, it returns a *ParseError if s contains a byte that is not allowed in a query,
+// a `&` or a `=` which separate the pairs, a `+`, or a `%` that is not followed by 2 hex digits.
+// Split a query into its pairs before their names and values are decoded, see QueryParams.
+func UnescapeQueryComponent(s string) (string, error) {
+	return checkAndUnescape(s, ComponentQuery, "query", isNotValidQueryComponentChar)
+}
+
+// UnescapeFragment decodes a fragment that was encoded by EscapeFragment, it returns a *ParseError
+// if s contains a byte that is not allowed in a fragment, or a `%` that is not followed by 2 hex digits
+func UnescapeFragment(s string) (string, error) {
+	return checkAndUnescape(s, ComponentFragment, "fragment", isNotValidQueryOrFragmentChar)
+}
+
+// checkAndUnescape checks the bytes of s, which is a part of the given component, and decodes its pct-encoded octets
+func checkAndUnescape(s string, component Component, rule string, isNotValid func(byte) bool) (string, error) {
+	if err := checkChars(s, component, rule, isNotValid); err != nil {
+		return "", err
+	}
+	return unescape(s, component)
+}
+
+// escape percent encodes every byte of s for which isNotValid returns true, or which is a `%`
+// https://tools.ietf.org/html/rfc3986#section-2.1
+func escape(s string, isNotValid func(byte) bool) string {
+	// count the bytes that need to be encoded, most inputs need none
+	count := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' || isNotValid(s[i]) {
+			count = count + 1
+		}
+	}
+
+	if count == 0 {
+		return s
+	}
+
+	var buf strings.Builder
+	buf.Grow(len(s) + 2*count)
+
+	for i := 0; i < len(s); i++ {
+		char := s[i]
+		if char == '%' || isNotValid(char) {
+			buf.WriteByte('%')                // nolint, returned error is always nil
+			buf.WriteByte(upperhex[char>>4])  // nolint, returned error is always nil
+			buf.WriteByte(upperhex[char&0xF]) // nolint, returned error is always nil
+		} else {
+			buf.WriteByte(char) // nolint, returned error is always nil
+		}
+	}
+
+	return buf.String()
+}
+
+// unescape decodes the pct-encoded octets in s, which is a part of the given component of a DID URL,
+// it returns a *ParseError if a `%` is not followed by 2 hex digits
+func unescape(s string, component Component) (string, error) {
+	if strings.IndexByte(s, '%') < 0 {
+		return s, nil
+	}
+
+	var buf strings.Builder
+	buf.Grow(len(s))
+
+	for i := 0; i < len(s); i++ {
+		char := s[i]
+		if char != '%' {
+			buf.WriteByte(char) // nolint, returned error is always nil
+			continue
+		}
+
+		// a % must be followed by 2 hex digits
+		if i+2 >= len(s) || isNotHexDigit(s[i+1]) || isNotHexDigit(s[i+2]) {
+			return "", newParseError(s, i, component, "pct-encoded", ErrInvalidPercentEncoding,
+				"%% is not followed by 2 hex digits")
+		}
+
+		buf.WriteByte(unhex(s[i+1])<<4 | unhex(s[i+2])) // nolint, returned error is always nil
+		i = i + 2
+	}
+
+	return buf.String(), nil
+}
+
+// isNotValidQueryComponentChar returns true if a byte is not allowed in a query param name or value,
+// in addition to the chars that are not allowed in a query `&` and `=` separate params,
+// and `+` is decoded as a space by HTML forms
+func isNotValidQueryComponentChar(char byte) bool {
+	return isNotValidQueryOrFragmentChar(char) || char == '&' || char == '=' || char == '+'
+}
+
+// unhex returns the value of a hex digit
+func unhex(char byte) byte {
+	switch {
+	case char >= '0' && char <= '9':
+		return char - '0'
+	case char >= 'a' && char <= 'f':
+		return char - 'a' + 10
+	default:
+		return char - 'A' + 10
+	}
+}
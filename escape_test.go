@@ -0,0 +1,101 @@
+package did
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		input    string
+		idString string
+		segment  string
+		query    string
+		fragment string
+	}{
+		{"", "", "", "", ""},
+		{"alice@example.com", "alice%40example.com", "alice@example.com", "alice@example.com", "alice@example.com"},
+		{"example.com:3000", "example.com%3A3000", "example.com:3000", "example.com:3000", "example.com:3000"},
+		{"6ba7b810-9dad-11d1-80b4-00c04fd430c8", "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+			"6ba7b810-9dad-11d1-80b4-00c04fd430c8", "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+			"6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{"a/b?c#d", "a%2Fb%3Fc%23d", "a%2Fb%3Fc%23d", "a/b?c%23d", "a/b?c%23d"},
+		{"a=b&c+d", "a%3Db%26c%2Bd", "a=b&c+d", "a%3Db%26c%2Bd", "a=b&c+d"},
+		{"100% ~ü", "100%25%20%7E%C3%BC", "100%25%20~%C3%BC", "100%25%20~%C3%BC", "100%25%20~%C3%BC"},
+	}
+
+	for _, test := range tests {
+		assert(t, test.idString, EscapeIDString(test.input), "Input: %s", test.input)
+		assert(t, test.segment, EscapePathSegment(test.input), "Input: %s", test.input)
+		assert(t, test.query, EscapeQueryComponent(test.input), "Input: %s", test.input)
+		assert(t, test.fragment, EscapeFragment(test.input), "Input: %s", test.input)
+
+		for _, unescape := range []struct {
+			name string
+			f    func(string) (string, error)
+			s    string
+		}{
+			{"UnescapeIDString", UnescapeIDString, test.idString},
+			{"UnescapePathSegment", UnescapePathSegment, test.segment},
+			{"UnescapeQueryComponent", UnescapeQueryComponent, test.query},
+			{"UnescapeFragment", UnescapeFragment, test.fragment},
+		} {
+			s, err := unescape.f(unescape.s)
+			assert(t, nil, err, "%s: %s", unescape.name, unescape.s)
+			assert(t, test.input, s, "%s: %s", unescape.name, unescape.s)
+		}
+
+		// the escaped forms are valid in their component of a DID URL
+		input := "did:example:" + test.idString + "x/" + test.segment + "?" + test.query + "=" + test.query +
+			"#" + test.fragment
+		d, err := Parse(input)
+		assert(t, nil, err, "Input: %s", input)
+		assert(t, []string{test.idString + "x"}, d.IDStrings, "Input: %s", input)
+
+		var segments []string
+		for segment := range d.Segments() {
+			segments = append(segments, segment)
+		}
+		assert(t, []string{test.input}, segments, "Input: %s", input)
+
+		for name, value := range d.QueryParams() {
+			assert(t, test.input, name, "Input: %s", input)
+			assert(t, test.input, value, "Input: %s", input)
+		}
+	}
+}
+
+func TestUnescapeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		f      func(string) (string, error)
+		input  string
+		offset int
+		err    error
+	}{
+		{"UnescapeIDString", UnescapeIDString, "a:b", 1, ErrInvalidCharacter},
+		{"UnescapeIDString", UnescapeIDString, "a%2", 1, ErrInvalidPercentEncoding},
+		{"UnescapePathSegment", UnescapePathSegment, "a/b", 1, ErrInvalidCharacter},
+		{"UnescapePathSegment", UnescapePathSegment, "%zz", 0, ErrInvalidPercentEncoding},
+		{"UnescapeQueryComponent", UnescapeQueryComponent, "a b", 1, ErrInvalidCharacter},
+		{"UnescapeQueryComponent", UnescapeQueryComponent, "a&b=c", 1, ErrInvalidCharacter},
+		{"UnescapeQueryComponent", UnescapeQueryComponent, "b=c", 1, ErrInvalidCharacter},
+		{"UnescapeQueryComponent", UnescapeQueryComponent, "a+b", 1, ErrInvalidCharacter},
+		{"UnescapeFragment", UnescapeFragment, "a#b", 1, ErrInvalidCharacter},
+	}
+
+	for _, test := range tests {
+		s, err := test.f(test.input)
+		assert(t, "", s, "%s: %s", test.name, test.input)
+		assert(t, true, errors.Is(err, test.err), "%s: %s: %v", test.name, test.input, err)
+
+		var pe *ParseError
+		assert(t, true, errors.As(err, &pe), "%s: %s", test.name, test.input)
+		assert(t, test.offset, pe.Offset, "%s: %s", test.name, test.input)
+	}
+
+	// a `+` is encoded by EscapeQueryComponent, it is not decoded as a space
+	s, err := UnescapeQueryComponent("a%2Bb%26c%3Dd")
+	assert(t, nil, err)
+	assert(t, "a+b&c=d", s)
+}
@@ -1,6 +1,7 @@
 package did_test
 
 import (
+	"errors"
 	"fmt"
 	"log"
 
@@ -43,6 +44,26 @@ func ExampleParse_withFragment() {
 	// Output: Method - example, ID - q7ckgxeq1lxmra0r, Fragment - keys-1
 }
 
+func ExampleParseWithOptions() {
+	d, err := did.ParseWithOptions("did:example:q7ckgxeq1lxmra0r;service=agent",
+		did.ParseOptions{Grammar: did.GrammarLegacyCCG})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Method - %s, ID - %s, Param - %s", d.Method, d.ID, d.Params[0].String())
+	// Output: Method - example, ID - q7ckgxeq1lxmra0r, Param - service=agent
+}
+
+func ExampleParseError() {
+	_, err := did.Parse("did:example:q7ckgxeq1lxmra0r/a^b")
+
+	var pe *did.ParseError
+	if errors.As(err, &pe) {
+		fmt.Printf("Component - %s, Offset - %d, Rule - %s", pe.Component, pe.Offset, pe.Rule)
+	}
+	// Output: Component - path, Offset - 30, Rule - pchar
+}
+
 func ExampleDID_String() {
 	d := &did.DID{Method: "example", ID: "q7ckgxeq1lxmra0r"}
 	fmt.Println(d.String())
@@ -90,3 +111,105 @@ func ExampleDID_IsURL_noPathOrFragment() {
 	fmt.Println(d.IsURL())
 	// Output: false
 }
+
+func ExampleDID_ResolveReference() {
+	base, err := did.Parse("did:example:q7ckgxeq1lxmra0r")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ref, err := did.ParseReference("#keys-1")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(base.ResolveReference(ref))
+	// Output: did:example:q7ckgxeq1lxmra0r#keys-1
+}
+
+func ExampleFindAll() {
+	text := "The issuer (did:example:q7ckgxeq1lxmra0r) signed with did:example:q7ckgxeq1lxmra0r#keys-1."
+	for _, match := range did.FindAll(text, -1) {
+		fmt.Println(match.Start, match.DID)
+	}
+	// Output:
+	// 12 did:example:q7ckgxeq1lxmra0r
+	// 54 did:example:q7ckgxeq1lxmra0r#keys-1
+}
+
+func ExampleNew() {
+	d, err := did.New("example", "q7ckgxeq1lxmra0r")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	key, err := d.WithFragment("keys-1")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(key)
+	fmt.Println(key.Base())
+	// Output:
+	// did:example:q7ckgxeq1lxmra0r#keys-1
+	// did:example:q7ckgxeq1lxmra0r
+}
+
+func ExamplePattern_Match() {
+	p := did.MustCompile("did:example:org:{tenant}:*#key-*")
+
+	d, err := did.Parse("did:example:org:acme:users:alice#key-1")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	bindings, ok := p.Match(d)
+	fmt.Println(ok, bindings["tenant"])
+	// Output: true acme
+}
+
+func ExampleSet_Prefix() {
+	var allowed did.Set
+	for _, s := range []string{"did:example:org:acme:bob", "did:example:org:acme:alice", "did:example:org:other"} {
+		d, err := did.Parse(s)
+		if err != nil {
+			log.Fatal(err)
+		}
+		allowed.Add(d)
+	}
+
+	prefix, err := did.Parse("did:example:org:acme")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for d := range allowed.Prefix(prefix) {
+		fmt.Println(d)
+	}
+	// Output:
+	// did:example:org:acme:alice
+	// did:example:org:acme:bob
+}
+
+func ExampleDocument_Validate() {
+	doc, err := did.DecodeJSON([]byte(`{
+		"id": "did:example:123",
+		"verificationMethod": [{
+			"id": "#key-1",
+			"type": "Multikey",
+			"controller": "did:example:123#key-1",
+			"publicKeyMultibase": "z6Mk"
+		}],
+		"authentication": ["#key-2"]
+	}`), did.DecodeOptions{Strict: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, problem := range doc.Validate() {
+		fmt.Println(problem.Code, problem.Pointer)
+	}
+	// Output:
+	// controller-not-did /verificationMethod/0/controller
+	// method-not-found /authentication/0
+}
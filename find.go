@@ -0,0 +1,199 @@
+package did
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+)
+
+// A Match is a DID or a DID URL that was found in text
+type Match struct {
+	// Start is the offset of the first byte of the DID in the text
+	Start int
+
+	// End is the offset of the byte that follows the DID in the text
+	End int
+
+	// DID is the parsed DID
+	DID *DID
+}
+
+// FindAll returns the DIDs and DID URLs in text, in the order in which they appear.
+// If n >= 0, FindAll returns at most n matches, a negative n returns all matches.
+// A match is the longest valid DID URL that begins with `did:`, which must not be preceded
+// by a letter or a digit. Punctuation that ends a sentence or encloses the DID, like the
+// `.` and `)` in "(see did:example:123).", is not included in the match.
+func FindAll(text string, n int) []Match {
+	var matches []Match
+
+	for i := 0; n < 0 || len(matches) < n; {
+		index := strings.Index(text[i:], "did:")
+		if index < 0 {
+			break
+		}
+		start := i + index
+
+		if start > 0 && !(isNotAlpha(text[start-1]) && isNotDigit(text[start-1])) {
+			// did: is part of a longer word
+			i = start + 4
+			continue
+		}
+
+		end := start + didURLExtent(text[start:])
+		length, d := longestMatch(text[start:end])
+		if d == nil {
+			i = start + 4
+			continue
+		}
+
+		matches = append(matches, Match{Start: start, End: start + length, DID: d})
+		i = start + length
+	}
+
+	return matches
+}
+
+// didURLExtent returns the number of bytes at the start of text that are allowed anywhere in a DID URL
+func didURLExtent(text string) int {
+	for i := 0; i < len(text); i++ {
+		char := text[i]
+		if isNotValidQueryOrFragmentChar(char) && char != '%' && char != '#' {
+			return i
+		}
+	}
+	return len(text)
+}
+
+// longestMatch returns the length and the parsed DID of the longest valid DID URL at the start of
+// candidate, using the offset of a parse error to find where the DID URL ends. It returns a nil DID
+// if candidate does not begin with a valid DID.
+func longestMatch(candidate string) (int, *DID) {
+	for {
+		candidate = trimPunctuation(candidate)
+
+		d, err := Parse(candidate)
+		if err == nil {
+			return len(candidate), d
+		}
+
+		// the parser stops at the first byte that is not valid, the input before it may be a valid DID URL
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Component <= ComponentMethod {
+			return 0, nil
+		}
+
+		end := pe.Offset
+		if end >= len(candidate) {
+			// the error is at the end of the input, ex- a trailing `:`
+			end = len(candidate) - 1
+		}
+		candidate = candidate[:end]
+	}
+}
+
+// trimPunctuation removes punctuation from the end of a candidate DID URL,
+// like a linkifier does for URLs in text
+func trimPunctuation(candidate string) string {
+	for len(candidate) > 0 {
+		switch candidate[len(candidate)-1] {
+		case '.', ',', ':', ';', '!', '?', '\'', '*':
+		case ')':
+			// only remove a closing parenthesis that does not have a matching opening one in the DID
+			if strings.Count(candidate, "(") >= strings.Count(candidate, ")") {
+				return candidate
+			}
+		default:
+			return candidate
+		}
+		candidate = candidate[:len(candidate)-1]
+	}
+	return candidate
+}
+
+// A Scanner reads the DIDs and DID URLs in a stream of text, it finds the same matches as FindAll.
+// The offsets of a Match are offsets in the stream. Successive calls to the Scan method step through
+// the matches, Scan returns false at the end of the stream or if there is an error reading it.
+type Scanner struct {
+	scanner *bufio.Scanner
+	offset  int   // offset in the stream of the data that is passed to split
+	prev    byte  // the last byte before the data that is passed to split
+	match   Match // the last match found by split
+}
+
+// NewScanner returns a new Scanner that reads from r. The longest DID URL it can find is
+// bufio.MaxScanTokenSize bytes long.
+func NewScanner(r io.Reader) *Scanner {
+	s := &Scanner{scanner: bufio.NewScanner(r)}
+	s.scanner.Split(s.split)
+	return s
+}
+
+// Scan advances the Scanner to the next match, which will then be available through the Match method.
+// It returns false when there are no more matches.
+func (s *Scanner) Scan() bool {
+	return s.scanner.Scan()
+}
+
+// Match returns the most recent match found by a call to Scan
+func (s *Scanner) Match() Match {
+	return s.match
+}
+
+// Err returns the first error that was encountered by the Scanner, other than io.EOF
+func (s *Scanner) Err() error {
+	return s.scanner.Err()
+}
+
+// split is a bufio.SplitFunc that returns the next DID URL in data as a token
+func (s *Scanner) split(data []byte, atEOF bool) (int, []byte, error) {
+	advance, token := s.next(data, atEOF)
+	if advance > 0 {
+		s.offset = s.offset + advance
+		s.prev = data[advance-1]
+	}
+	return advance, token, nil
+}
+
+// next finds the next DID URL in data and returns the number of bytes to advance and the DID URL,
+// if data ends before the end of a DID URL it returns a nil token to request more data
+func (s *Scanner) next(data []byte, atEOF bool) (int, []byte) {
+	i := 0
+	for {
+		index := bytes.Index(data[i:], []byte("did:"))
+		if index < 0 {
+			if atEOF {
+				return len(data), nil
+			}
+			// keep the last 3 bytes, they may be the start of a did: that continues in the next read
+			return max(len(data)-3, 0), nil
+		}
+		start := i + index
+
+		prev := s.prev
+		if start > 0 {
+			prev = data[start-1]
+		}
+		if (start > 0 || s.offset > 0) && !(isNotAlpha(prev) && isNotDigit(prev)) {
+			// did: is part of a longer word
+			i = start + 4
+			continue
+		}
+
+		end := start + didURLExtent(string(data[start:]))
+		if end == len(data) && !atEOF {
+			// the DID URL may continue in the next read
+			return start, nil
+		}
+
+		length, d := longestMatch(string(data[start:end]))
+		if d == nil {
+			i = start + 4
+			continue
+		}
+
+		s.match = Match{Start: s.offset + start, End: s.offset + start + length, DID: d}
+		return start + length, data[start : start+length]
+	}
+}
@@ -0,0 +1,88 @@
+package did
+
+import (
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestFindAll(t *testing.T) {
+	examples := []struct {
+		text     string
+		expected []string
+	}{
+		{"", nil},
+		{"no dids here", nil},
+		{"did:example:123", []string{"did:example:123"}},
+		{"resolve did:example:123.", []string{"did:example:123"}},
+		{"is it did:example:123?", []string{"did:example:123"}},
+		{"(see did:example:123/a)", []string{"did:example:123/a"}},
+		{"see did:example:123/a_(b)", []string{"did:example:123/a_(b)"}},
+		{"\"did:example:123#key-1\", said the doc", []string{"did:example:123#key-1"}},
+		{"did:example:123, did:example:456; did:web:example.com!", []string{
+			"did:example:123", "did:example:456", "did:web:example.com",
+		}},
+		{"<did:example:123?versionId=1>", []string{"did:example:123?versionId=1"}},
+		{"did:example:123#a#b", []string{"did:example:123#a"}},
+		{"did:example:123%zz", []string{"did:example:123"}},
+		{"did:example:a:", []string{"did:example:a"}},
+		{"skipped xdid:example:123 and did:Example:123 and did:example", nil},
+		{"did:did:example:123", []string{"did:did:example:123"}},
+	}
+
+	for _, example := range examples {
+		var actual []string
+		for _, match := range FindAll(example.text, -1) {
+			assert(t, example.text[match.Start:match.End], match.DID.String(), "offsets for %q", example.text)
+			actual = append(actual, match.DID.String())
+		}
+		assert(t, example.expected, actual, "matches for %q", example.text)
+	}
+
+	t.Run("returns at most n matches", func(t *testing.T) {
+		text := "did:example:1 did:example:2 did:example:3"
+		assert(t, []Match(nil), FindAll(text, 0))
+		assert(t, 2, len(FindAll(text, 2)))
+		assert(t, 3, len(FindAll(text, 10)))
+	})
+
+	t.Run("reports byte offsets", func(t *testing.T) {
+		matches := FindAll("é did:example:123.", -1)
+		assert(t, 1, len(matches))
+		assert(t, 3, matches[0].Start)
+		assert(t, 18, matches[0].End)
+	})
+}
+
+func TestScanner(t *testing.T) {
+	text := "first did:example:123, then (did:web:example.com:user)\n" +
+		strings.Repeat("x", 5000) + " did:example:456/a?b#c. xdid:example:789 did:example:abc"
+
+	t.Run("finds the same matches as FindAll", func(t *testing.T) {
+		expected := FindAll(text, -1)
+		assert(t, 4, len(expected))
+
+		for _, reader := range []struct {
+			name   string
+			reader func() *Scanner
+		}{
+			{"whole", func() *Scanner { return NewScanner(strings.NewReader(text)) }},
+			{"one byte at a time", func() *Scanner { return NewScanner(iotest.OneByteReader(strings.NewReader(text))) }},
+		} {
+			var actual []Match
+			s := reader.reader()
+			for s.Scan() {
+				actual = append(actual, s.Match())
+			}
+			assert(t, nil, s.Err(), reader.name)
+			assert(t, expected, actual, reader.name)
+		}
+	})
+
+	t.Run("returns the error of the reader", func(t *testing.T) {
+		s := NewScanner(iotest.TimeoutReader(iotest.OneByteReader(strings.NewReader(text))))
+		for s.Scan() {
+		}
+		assert(t, iotest.ErrTimeout, s.Err())
+	})
+}
@@ -0,0 +1,172 @@
+package did
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// The seed corpus of each fuzz target is in testdata/fuzz/<target>, it holds the inputs of the tests in
+// did_test.go and identifiers from the W3C DID test suite https://github.com/w3c/did-test-suite.
+// To fuzz a target, run:
+//   go test -run XXX -fuzz FuzzParse
+
+// FuzzParse checks that Parse never panics, and that every way of parsing an input agrees with Parse
+func FuzzParse(f *testing.F) {
+	f.Fuzz(func(t *testing.T, input string) {
+		d, err := Parse(input)
+
+		if err != nil {
+			if d != nil {
+				t.Fatalf("Parse(%q) returned a DID and an error", input)
+			}
+
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("Parse(%q) returned an error that is not a *ParseError: %v", input, err)
+			}
+			if pe.Offset < 0 || pe.Offset > len(input) || pe.Input != input {
+				t.Fatalf("Parse(%q) returned an error at offset %d", input, pe.Offset)
+			}
+		}
+
+		if validateErr := Validate(input); !reflect.DeepEqual(err, validateErr) {
+			t.Fatalf("Validate(%q) = %v, Parse returned %v", input, validateErr, err)
+		}
+
+		bytesDID, bytesErr := ParseBytes([]byte(input))
+		if !reflect.DeepEqual(d, bytesDID) || !reflect.DeepEqual(err, bytesErr) {
+			t.Fatalf("ParseBytes(%q) does not agree with Parse", input)
+		}
+
+		into := &DID{IDStrings: []string{"x"}, Fragment: "x"}
+		intoErr := ParseInto(into, input)
+		if !reflect.DeepEqual(err, intoErr) || (err == nil && into.String() != d.String()) {
+			t.Fatalf("ParseInto(%q) does not agree with Parse", input)
+		}
+
+		// the other grammar and parsers must not panic either
+		_, _ = ParseWithOptions(input, ParseOptions{Grammar: GrammarLegacyCCG})
+		_, _ = ParseReference(input)
+		_, _, _ = ParseWithSpans(input, ParseOptions{})
+	})
+}
+
+// FuzzStringRoundTrip checks that parse(String(parse(x))) == parse(x)
+func FuzzStringRoundTrip(f *testing.F) {
+	f.Fuzz(func(t *testing.T, input string) {
+		for _, opts := range []ParseOptions{{}, {Grammar: GrammarLegacyCCG}} {
+			d, err := ParseWithOptions(input, opts)
+			if err != nil {
+				continue
+			}
+
+			s := d.String()
+			roundTrip, err := ParseWithOptions(s, opts)
+			if err != nil {
+				t.Fatalf("String of %q is %q, which does not parse: %v", input, s, err)
+			}
+			if !reflect.DeepEqual(d, roundTrip) {
+				t.Fatalf("String of %q is %q, which parses to %#v instead of %#v", input, s, roundTrip, d)
+			}
+		}
+	})
+}
+
+// FuzzParamString checks that a Param made of param-chars is encoded into a string that parses back into it
+func FuzzParamString(f *testing.F) {
+	f.Fuzz(func(t *testing.T, name, value string) {
+		param := Param{Name: name, Value: value}
+		s := param.String()
+
+		if name == "" {
+			if s != "" {
+				t.Fatalf("String of a Param without a name is %q", s)
+			}
+			return
+		}
+
+		if checkChars(name, ComponentParam, "param-char", isNotValidParamChar) != nil ||
+			checkChars(value, ComponentParam, "param-char", isNotValidParamChar) != nil {
+			return
+		}
+
+		d, err := ParseWithOptions("did:a:1;"+s, ParseOptions{Grammar: GrammarLegacyCCG})
+		if err != nil {
+			t.Fatalf("String of %#v is %q, which does not parse: %v", param, s, err)
+		}
+		if len(d.Params) != 1 || d.Params[0] != param {
+			t.Fatalf("String of %#v is %q, which parses to %#v", param, s, d.Params)
+		}
+	})
+}
+
+// FuzzResolveReference checks that resolving a reference against a DID returns a DID URL without dot segments,
+// which resolves to itself
+func FuzzResolveReference(f *testing.F) {
+	f.Fuzz(func(t *testing.T, base, reference string) {
+		d, err := Parse(base)
+		if err != nil {
+			return
+		}
+		ref, err := ParseReference(reference)
+		if err != nil {
+			return
+		}
+
+		resolved := d.ResolveReference(ref)
+		s := resolved.String()
+
+		parsed, err := Parse(s)
+		if err != nil {
+			t.Fatalf("%q resolved against %q is %q, which does not parse: %v", reference, base, s, err)
+		}
+
+		if ref.Method == "" && (resolved.Method != d.Method || resolved.ID != d.ID) {
+			t.Fatalf("%q resolved against %q is %q, which is not a DID URL of the base DID", reference, base, s)
+		}
+
+		for _, segment := range parsed.PathSegments {
+			if segment == "." || segment == ".." {
+				t.Fatalf("%q resolved against %q is %q, which has dot segments", reference, base, s)
+			}
+		}
+
+		if again := d.ResolveReference(parsed).String(); again != s {
+			t.Fatalf("%q resolved against %q is %q, which resolves to %q", reference, base, s, again)
+		}
+	})
+}
+
+// FuzzNormalize checks that a normalized DID is equal to the DID, and that normalization is idempotent
+func FuzzNormalize(f *testing.F) {
+	f.Fuzz(func(t *testing.T, input string) {
+		d, err := Parse(input)
+		if err != nil {
+			return
+		}
+
+		normalized := d.Normalize()
+		s := normalized.String()
+
+		parsed, err := Parse(s)
+		if err != nil {
+			t.Fatalf("normal form of %q is %q, which does not parse: %v", input, s, err)
+		}
+		if again := parsed.Normalize().String(); again != s {
+			t.Fatalf("normal form of %q is %q, which normalizes to %q", input, s, again)
+		}
+
+		if !Equal(d, normalized) || !Equal(d, parsed) {
+			t.Fatalf("%q is not equal to its normal form %q", input, s)
+		}
+
+		// the normal form only uses uppercase hex digits
+		for i := 0; i+2 < len(s); i++ {
+			if s[i] == '%' && strings.ToUpper(s[i+1:i+3]) != s[i+1:i+3] {
+				t.Fatalf("normal form of %q is %q, which has lowercase hex digits", input, s)
+			}
+		}
+	})
+}
@@ -0,0 +1,3 @@
+module github.com/ockam-network/did
+
+go 1.23
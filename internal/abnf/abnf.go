@@ -0,0 +1,495 @@
+// Package abnf is an interpreter for grammars written in ABNF, as described in RFC 5234 and RFC 7405.
+// It is used to test the hand written DID parser against did.abnf, so it favours simplicity over speed.
+// https://tools.ietf.org/html/rfc5234
+// https://tools.ietf.org/html/rfc7405
+package abnf
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// coreRules are the core rules of ABNF, which every grammar may use without defining them
+// https://tools.ietf.org/html/rfc5234#appendix-B.1
+const coreRules = `
+ALPHA  = %x41-5A / %x61-7A
+BIT    = "0" / "1"
+CHAR   = %x01-7F
+CR     = %x0D
+CRLF   = CR LF
+CTL    = %x00-1F / %x7F
+DIGIT  = %x30-39
+DQUOTE = %x22
+HEXDIG = DIGIT / "A" / "B" / "C" / "D" / "E" / "F"
+HTAB   = %x09
+LF     = %x0A
+LWSP   = *(WSP / CRLF WSP)
+OCTET  = %x00-FF
+SP     = %x20
+VCHAR  = %x21-7E
+WSP    = SP / HTAB
+`
+
+// A Grammar is a set of named rules
+type Grammar struct {
+	rules map[string]node
+}
+
+// Parse parses the rules of an ABNF grammar, the core rules are added to them.
+// Rule names are case insensitive, a rule may be defined once with `=` and extended with `=/`.
+func Parse(src string) (*Grammar, error) {
+	g := &Grammar{rules: make(map[string]node)}
+
+	if err := g.parse(coreRules); err != nil {
+		return nil, err
+	}
+	if err := g.parse(src); err != nil {
+		return nil, err
+	}
+
+	// every rule that is referenced must be defined
+	for name, rule := range g.rules {
+		if err := g.checkRefs(rule); err != nil {
+			return nil, fmt.Errorf("abnf: rule %s: %v", name, err)
+		}
+	}
+
+	return g, nil
+}
+
+// Rules returns the sorted names of the rules of the grammar, in lower case
+func (g *Grammar) Rules() []string {
+	names := make([]string, 0, len(g.rules))
+	for name := range g.rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Match returns true if the whole input matches the named rule
+func (g *Grammar) Match(rule, input string) (bool, error) {
+	n, ok := g.rules[strings.ToLower(rule)]
+	if !ok {
+		return false, fmt.Errorf("abnf: rule %s is not defined", rule)
+	}
+
+	m := &matcher{grammar: g, input: input, memo: make(map[memoKey][]int)}
+	for _, end := range n.match(m, 0) {
+		if end == len(input) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// parse adds the rules in src to the grammar
+func (g *Grammar) parse(src string) error {
+	for _, line := range joinRules(src) {
+		p := &ruleParser{src: line}
+
+		name := p.ruleName()
+		if name == "" {
+			return fmt.Errorf("abnf: rule does not begin with a rule name: %q", line)
+		}
+		name = strings.ToLower(name)
+
+		p.skipSpace()
+		incremental := false
+		switch {
+		case strings.HasPrefix(p.src[p.pos:], "=/"):
+			incremental = true
+			p.pos = p.pos + 2
+		case strings.HasPrefix(p.src[p.pos:], "="):
+			p.pos = p.pos + 1
+		default:
+			return fmt.Errorf("abnf: rule %s does not have `=` after its name", name)
+		}
+
+		elements, err := p.alternation()
+		if err != nil {
+			return fmt.Errorf("abnf: rule %s: %v", name, err)
+		}
+		p.skipSpace()
+		if p.pos < len(p.src) {
+			return fmt.Errorf("abnf: rule %s: unexpected %q", name, p.src[p.pos:])
+		}
+
+		existing, defined := g.rules[name]
+		switch {
+		case incremental && !defined:
+			return fmt.Errorf("abnf: rule %s is extended with `=/` before it is defined", name)
+		case incremental:
+			g.rules[name] = alternation{existing, elements}
+		default:
+			// a grammar may redefine a core rule, but not one of its own rules
+			if _, core := g.rules[name]; core && !isCoreRule(name) {
+				return fmt.Errorf("abnf: rule %s is defined twice", name)
+			}
+			g.rules[name] = elements
+		}
+	}
+
+	return nil
+}
+
+// checkRefs returns an error if a node references a rule that is not defined
+func (g *Grammar) checkRefs(n node) error {
+	switch n := n.(type) {
+	case alternation:
+		for _, alt := range n {
+			if err := g.checkRefs(alt); err != nil {
+				return err
+			}
+		}
+	case concatenation:
+		for _, element := range n {
+			if err := g.checkRefs(element); err != nil {
+				return err
+			}
+		}
+	case repetition:
+		return g.checkRefs(n.element)
+	case ruleRef:
+		if _, ok := g.rules[string(n)]; !ok {
+			return fmt.Errorf("rule %s is not defined", string(n))
+		}
+	}
+	return nil
+}
+
+// isCoreRule returns true if name is the name of one of the core rules
+func isCoreRule(name string) bool {
+	for _, line := range joinRules(coreRules) {
+		if strings.ToLower(strings.Fields(line)[0]) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// joinRules removes comments from src and returns its rules, each on a single line.
+// A line that begins with white space continues the rule on the previous line.
+func joinRules(src string) []string {
+	var rules []string
+
+	for _, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		line = stripComment(line)
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if (line[0] == ' ' || line[0] == '\t') && len(rules) > 0 {
+			rules[len(rules)-1] = rules[len(rules)-1] + " " + strings.TrimSpace(line)
+			continue
+		}
+
+		rules = append(rules, strings.TrimSpace(line))
+	}
+
+	return rules
+}
+
+// stripComment removes a comment from the end of a line, a `;` in a quoted string or a prose value does not
+// begin a comment
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch {
+		case quote != 0:
+			if line[i] == quote {
+				quote = 0
+			}
+		case line[i] == '"':
+			quote = '"'
+		case line[i] == '<':
+			quote = '>'
+		case line[i] == ';':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// ruleParser is a recursive descent parser of the elements of a rule
+// from the grammar:
+//   elements      = alternation
+//   alternation   = concatenation *( "/" concatenation )
+//   concatenation = repetition *( 1*WSP repetition )
+//   repetition    = [ repeat ] element
+//   repeat        = 1*DIGIT / ( *DIGIT "*" *DIGIT )
+//   element       = rulename / group / option / char-val / num-val / prose-val
+type ruleParser struct {
+	src string
+	pos int
+}
+
+func (p *ruleParser) skipSpace() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos = p.pos + 1
+	}
+}
+
+// peek returns the next byte that is not white space, or 0 at the end of the rule
+func (p *ruleParser) peek() byte {
+	p.skipSpace()
+	if p.pos == len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// ruleName parses a rule name
+// from the grammar:
+//   rulename = ALPHA *( ALPHA / DIGIT / "-" )
+func (p *ruleParser) ruleName() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.src) {
+		char := p.src[p.pos]
+		isAlpha := (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+		if !isAlpha && (p.pos == start || !(char >= '0' && char <= '9') && char != '-') {
+			break
+		}
+		p.pos = p.pos + 1
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *ruleParser) alternation() (node, error) {
+	var alts alternation
+
+	for {
+		concat, err := p.concatenation()
+		if err != nil {
+			return nil, err
+		}
+		alts = append(alts, concat)
+
+		if p.peek() != '/' {
+			break
+		}
+		p.pos = p.pos + 1
+	}
+
+	if len(alts) == 1 {
+		return alts[0], nil
+	}
+	return alts, nil
+}
+
+func (p *ruleParser) concatenation() (node, error) {
+	var elements concatenation
+
+	for {
+		char := p.peek()
+		if char == 0 || char == '/' || char == ')' || char == ']' {
+			break
+		}
+
+		element, err := p.repetition()
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+	}
+
+	switch len(elements) {
+	case 0:
+		return nil, fmt.Errorf("expected an element at %q", p.src[p.pos:])
+	case 1:
+		return elements[0], nil
+	default:
+		return elements, nil
+	}
+}
+
+func (p *ruleParser) repetition() (node, error) {
+	p.skipSpace()
+
+	min, max, repeat := 1, 1, false
+	if digits := p.digits(); digits != "" {
+		min, max, repeat = atoi(digits), atoi(digits), true
+	} else {
+		min = -1
+	}
+	if p.pos < len(p.src) && p.src[p.pos] == '*' {
+		p.pos = p.pos + 1
+		if min < 0 {
+			min = 0
+		}
+		max = -1
+		if digits := p.digits(); digits != "" {
+			max = atoi(digits)
+		}
+		repeat = true
+	}
+	if min < 0 {
+		min = 1
+	}
+
+	element, err := p.element()
+	if err != nil {
+		return nil, err
+	}
+
+	if !repeat {
+		return element, nil
+	}
+	if max >= 0 && max < min {
+		return nil, fmt.Errorf("repetition maximum %d is less than its minimum %d", max, min)
+	}
+	return repetition{element: element, min: min, max: max}, nil
+}
+
+func (p *ruleParser) digits() string {
+	start := p.pos
+	for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+		p.pos = p.pos + 1
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *ruleParser) element() (node, error) {
+	switch p.peek() {
+	case '(':
+		return p.group(')')
+	case '[':
+		group, err := p.group(']')
+		if err != nil {
+			return nil, err
+		}
+		return repetition{element: group, min: 0, max: 1}, nil
+	case '"':
+		return p.charVal(false)
+	case '%':
+		return p.percentVal()
+	case '<':
+		return nil, fmt.Errorf("prose values are not supported: %q", p.src[p.pos:])
+	}
+
+	name := p.ruleName()
+	if name == "" {
+		return nil, fmt.Errorf("expected an element at %q", p.src[p.pos:])
+	}
+	return ruleRef(strings.ToLower(name)), nil
+}
+
+// group parses the elements in parentheses or brackets, which end with the given byte
+func (p *ruleParser) group(end byte) (node, error) {
+	p.pos = p.pos + 1
+
+	elements, err := p.alternation()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek() != end {
+		return nil, fmt.Errorf("expected %c at %q", end, p.src[p.pos:])
+	}
+	p.pos = p.pos + 1
+
+	return elements, nil
+}
+
+// charVal parses a quoted string, which is case insensitive unless it is prefixed with %s
+// from the grammar:
+//   char-val = DQUOTE *(%x20-21 / %x23-7E) DQUOTE
+func (p *ruleParser) charVal(caseSensitive bool) (node, error) {
+	end := strings.IndexByte(p.src[p.pos+1:], '"')
+	if end < 0 {
+		return nil, fmt.Errorf("string is not terminated: %q", p.src[p.pos:])
+	}
+
+	value := p.src[p.pos+1 : p.pos+1+end]
+	p.pos = p.pos + end + 2
+
+	return literal{value: value, caseSensitive: caseSensitive}, nil
+}
+
+// percentVal parses a num-val, or a char-val with a case sensitivity prefix
+// from the grammar:
+//   num-val = "%" (bin-val / dec-val / hex-val)
+//   hex-val = "x" 1*HEXDIG [ 1*("." 1*HEXDIG) / ("-" 1*HEXDIG) ]
+//   char-val = case-insensitive-string / case-sensitive-string
+//   case-sensitive-string = "%s" quoted-string
+func (p *ruleParser) percentVal() (node, error) {
+	if p.pos+2 > len(p.src) {
+		return nil, fmt.Errorf("expected a value after %%")
+	}
+
+	base := 0
+	switch p.src[p.pos+1] {
+	case 's', 'S', 'i', 'I':
+		caseSensitive := p.src[p.pos+1] == 's' || p.src[p.pos+1] == 'S'
+		p.pos = p.pos + 2
+		if p.pos == len(p.src) || p.src[p.pos] != '"' {
+			return nil, fmt.Errorf("expected a quoted string after %%s or %%i")
+		}
+		return p.charVal(caseSensitive)
+	case 'x', 'X':
+		base = 16
+	case 'd', 'D':
+		base = 10
+	case 'b', 'B':
+		base = 2
+	default:
+		return nil, fmt.Errorf("unknown value type %%%c", p.src[p.pos+1])
+	}
+	p.pos = p.pos + 2
+
+	first, err := p.number(base)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.src) && p.src[p.pos] == '-' {
+		p.pos = p.pos + 1
+		last, err := p.number(base)
+		if err != nil {
+			return nil, err
+		}
+		if last < first {
+			return nil, fmt.Errorf("value range %d-%d is empty", first, last)
+		}
+		return byteRange{first: first, last: last}, nil
+	}
+
+	value := []byte{first}
+	for p.pos < len(p.src) && p.src[p.pos] == '.' {
+		p.pos = p.pos + 1
+		next, err := p.number(base)
+		if err != nil {
+			return nil, err
+		}
+		value = append(value, next)
+	}
+
+	return literal{value: string(value), caseSensitive: true}, nil
+}
+
+// number parses a number in the given base, which must fit in a byte
+func (p *ruleParser) number(base int) (byte, error) {
+	start := p.pos
+	for p.pos < len(p.src) && strings.IndexByte("0123456789abcdefABCDEF", p.src[p.pos]) >= 0 {
+		p.pos = p.pos + 1
+	}
+
+	n, err := strconv.ParseUint(p.src[start:p.pos], base, 8)
+	if err != nil {
+		return 0, fmt.Errorf("value %q is not a base %d number between 0 and 255", p.src[start:p.pos], base)
+	}
+	return byte(n), nil
+}
+
+func atoi(digits string) int {
+	n, err := strconv.Atoi(digits)
+	if err != nil {
+		// digits are always made of 0-9, only a huge number does not fit in an int
+		panic(err)
+	}
+	return n
+}
@@ -0,0 +1,121 @@
+package abnf
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+const testGrammar = `
+; a comment
+greeting = %s"hi" SP name [ "!" ] ; a trailing comment
+name     = 1*3ALPHA
+         / quoted
+quoted   = DQUOTE *( %x20-21 / %x23-7E ) DQUOTE
+list     = name *( ";" name )
+list     =/ "<" %d60.62 ">"
+bits     = 2BIT
+`
+
+func TestParse(t *testing.T) {
+	g, err := Parse(testGrammar)
+	assert(t, nil, err)
+
+	tests := []struct {
+		rule     string
+		input    string
+		expected bool
+	}{
+		{"greeting", "hi bob", true},
+		{"greeting", "hi bob!", true},
+		{"greeting", "HI bob", false},
+		{"greeting", "hi bobby", false},
+		{"greeting", `hi "a; b"`, true},
+		{"greeting", "hi ", false},
+		{"name", "Bob", true},
+		{"NAME", "abc", true},
+		{"list", "a;bc;def", true},
+		{"list", "a;bc;", false},
+		{"list", "<<>>", true},
+		{"bits", "01", true},
+		{"bits", "0", false},
+		{"bits", "012", false},
+		{"quoted", "\"\xc3\"", false},
+		{"HEXDIG", "f", true},
+		{"HEXDIG", "g", false},
+	}
+
+	for _, test := range tests {
+		actual, err := g.Match(test.rule, test.input)
+		assert(t, nil, err)
+		assert(t, test.expected, actual, "%s: %q", test.rule, test.input)
+	}
+
+	t.Run("a case insensitive string only folds ASCII letters", func(t *testing.T) {
+		g, err := Parse(`k = "k"`)
+		assert(t, nil, err)
+		actual, err := g.Match("k", "\u212a")
+		assert(t, nil, err)
+		assert(t, false, actual)
+	})
+
+	_, err = g.Match("missing", "")
+	assert(t, "abnf: rule missing is not defined", fmt.Sprint(err))
+}
+
+func TestParseErrors(t *testing.T) {
+	grammars := []string{
+		`a = b`,
+		`a = "x`,
+		`a =/ "x"`,
+		`a = "x"` + "\n" + `a = "y"`,
+		`a = 3*2"x"`,
+		`a = ( "x"`,
+		`a = <prose>`,
+		`a = %x100`,
+		`a = %x42-41`,
+		`= "x"`,
+		`a "x"`,
+		`a = `,
+	}
+
+	for _, grammar := range grammars {
+		_, err := Parse(grammar)
+		assert(t, false, err == nil, "Grammar: %s", grammar)
+	}
+}
+
+func TestRules(t *testing.T) {
+	g, err := Parse(`a = "x"`)
+	assert(t, nil, err)
+	assert(t, []string{"a", "alpha", "bit", "char", "cr", "crlf", "ctl", "digit", "dquote", "hexdig", "htab",
+		"lf", "lwsp", "octet", "sp", "vchar", "wsp"}, g.Rules())
+}
+
+func assert(t *testing.T, expected interface{}, actual interface{}, args ...interface{}) {
+	if !reflect.DeepEqual(expected, actual) {
+		argsLength := len(args)
+		var message string
+
+		// if only one arg is present, treat it as the message
+		if argsLength == 1 {
+			message = args[0].(string)
+		}
+
+		// if more than one arg is present, treat it as format, args (like Printf)
+		if argsLength > 1 {
+			message = fmt.Sprintf(args[0].(string), args[1:]...)
+		}
+
+		// is message is not empty add some spacing
+		if message != "" {
+			message = "\t" + message + "\n\n"
+		}
+
+		_, file, line, _ := runtime.Caller(1)
+		fmt.Printf("%s:%d:\n\tExpected: %#v\n\tActual: %#v\n%s", filepath.Base(file), line, expected, actual, message)
+		t.FailNow()
+	}
+}
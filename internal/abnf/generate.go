@@ -0,0 +1,134 @@
+package abnf
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// maxDepth is the depth of nested rules at which a generator gives up, a grammar with recursive
+// rules may never generate a string that ends
+const maxDepth = 64
+
+// maxExtraRepetitions is the largest number of repetitions, above the minimum, that is generated
+// for a repetition without a maximum
+const maxExtraRepetitions = 4
+
+// errTooDeep is returned when a generated string would nest more than maxDepth rules
+var errTooDeep = errors.New("abnf: rules are nested too deeply")
+
+// generator holds the state of the generation of a string
+type generator struct {
+	grammar *Grammar
+	rand    *rand.Rand
+	out     strings.Builder
+}
+
+// Generate returns a random string that matches the named rule
+func (g *Grammar) Generate(rule string, r *rand.Rand) (string, error) {
+	n, ok := g.rules[strings.ToLower(rule)]
+	if !ok {
+		return "", fmt.Errorf("abnf: rule %s is not defined", rule)
+	}
+
+	gen := &generator{grammar: g, rand: r}
+	if err := n.generate(gen, 0); err != nil {
+		return "", err
+	}
+
+	return gen.out.String(), nil
+}
+
+// the bytes that Mutate inserts, they are the delimiters of URIs and DIDs, and bytes that are often
+// handled incorrectly by parsers
+const mutations = "%:/?#;=@&+.-_~!$'()*,[]<>\"{}|\\^` \t\x00\x7f\xc3\xa9AaZz09Ff"
+
+// Mutate returns a near-valid variant of s, which is s with a byte inserted, removed, replaced or duplicated
+func Mutate(s string, r *rand.Rand) string {
+	if s == "" {
+		return string(mutations[r.Intn(len(mutations))])
+	}
+
+	i := r.Intn(len(s))
+	char := mutations[r.Intn(len(mutations))]
+
+	switch r.Intn(4) {
+	case 0:
+		return s[:i] + string(char) + s[i:]
+	case 1:
+		return s[:i] + s[i+1:]
+	case 2:
+		return s[:i] + string(char) + s[i+1:]
+	default:
+		// duplicate a substring, ex- a `:` separated part
+		j := i + r.Intn(len(s)-i) + 1
+		return s[:j] + s[i:j] + s[j:]
+	}
+}
+
+func (n alternation) generate(g *generator, depth int) error {
+	return n[g.rand.Intn(len(n))].generate(g, depth)
+}
+
+func (n concatenation) generate(g *generator, depth int) error {
+	for _, element := range n {
+		if err := element.generate(g, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n repetition) generate(g *generator, depth int) error {
+	extra := maxExtraRepetitions
+	if n.max >= 0 {
+		extra = n.max - n.min
+	}
+
+	count := n.min
+	if extra > 0 {
+		count = count + g.rand.Intn(extra+1)
+	}
+
+	for i := 0; i < count; i++ {
+		if err := n.element.generate(g, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n ruleRef) generate(g *generator, depth int) error {
+	if depth == maxDepth {
+		return errTooDeep
+	}
+	return g.grammar.rules[string(n)].generate(g, depth+1)
+}
+
+func (n literal) generate(g *generator, depth int) error {
+	if n.caseSensitive {
+		g.out.WriteString(n.value) // nolint, returned error is always nil
+		return nil
+	}
+
+	// a case insensitive string may be generated in any case
+	for i := 0; i < len(n.value); i++ {
+		char := n.value[i]
+		if g.rand.Intn(2) == 0 {
+			switch {
+			case char >= 'a' && char <= 'z':
+				char = char - 'a' + 'A'
+			case char >= 'A' && char <= 'Z':
+				char = char - 'A' + 'a'
+			}
+		}
+		g.out.WriteByte(char) // nolint, returned error is always nil
+	}
+	return nil
+}
+
+func (n byteRange) generate(g *generator, depth int) error {
+	g.out.WriteByte(n.first + byte(g.rand.Intn(int(n.last-n.first)+1))) // nolint, returned error is always nil
+	return nil
+}
@@ -0,0 +1,47 @@
+package abnf
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	g, err := Parse(testGrammar)
+	assert(t, nil, err)
+
+	r := rand.New(rand.NewSource(1))
+	for _, rule := range []string{"greeting", "name", "quoted", "list", "bits", "HEXDIG"} {
+		for i := 0; i < 100; i++ {
+			s, err := g.Generate(rule, r)
+			assert(t, nil, err)
+
+			matches, err := g.Match(rule, s)
+			assert(t, nil, err)
+			assert(t, true, matches, "%s: %q", rule, s)
+		}
+	}
+
+	t.Run("returns an error if a recursive rule does not end", func(t *testing.T) {
+		g, err := Parse(`a = "(" a ")"`)
+		assert(t, nil, err)
+		_, err = g.Generate("a", r)
+		assert(t, errTooDeep, err)
+	})
+
+	_, err = g.Generate("missing", r)
+	assert(t, false, err == nil)
+}
+
+func TestMutate(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	changed := 0
+	for i := 0; i < 100; i++ {
+		mutated := Mutate("did:example:123", r)
+		if mutated != "did:example:123" {
+			changed = changed + 1
+		}
+	}
+	// a byte may be replaced by itself
+	assert(t, true, changed > 90)
+	assert(t, 1, len(Mutate("", r)))
+}
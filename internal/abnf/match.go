@@ -0,0 +1,181 @@
+package abnf
+
+import (
+	"sort"
+	"strings"
+)
+
+// a node is an element of a rule, it can match input and generate strings that it matches
+type node interface {
+	// match returns the sorted positions in the input at which a match of the node that begins at pos can end
+	match(m *matcher, pos int) []int
+
+	// generate appends a random string that the node matches to the generator's output
+	generate(g *generator, depth int) error
+}
+
+type (
+	// alternation matches any one of its nodes
+	alternation []node
+
+	// concatenation matches each of its nodes in turn
+	concatenation []node
+
+	// repetition matches its element at least min and at most max times, max is -1 if there is no maximum
+	repetition struct {
+		element  node
+		min, max int
+	}
+
+	// ruleRef matches the rule with the given lower case name
+	ruleRef string
+
+	// literal matches a string, which is case insensitive for the letters a-z unless caseSensitive is set
+	literal struct {
+		value         string
+		caseSensitive bool
+	}
+
+	// byteRange matches one byte between first and last
+	byteRange struct {
+		first, last byte
+	}
+)
+
+// matcher holds the state of a match of a grammar against an input
+type matcher struct {
+	grammar *Grammar
+	input   string
+	memo    map[memoKey][]int // the end positions of the rules that have been matched at each position
+}
+
+type memoKey struct {
+	rule string
+	pos  int
+}
+
+func (n alternation) match(m *matcher, pos int) []int {
+	var ends []int
+	for _, alt := range n {
+		ends = union(ends, alt.match(m, pos))
+	}
+	return ends
+}
+
+func (n concatenation) match(m *matcher, pos int) []int {
+	ends := []int{pos}
+	for _, element := range n {
+		var next []int
+		for _, end := range ends {
+			next = union(next, element.match(m, end))
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		ends = next
+	}
+	return ends
+}
+
+func (n repetition) match(m *matcher, pos int) []int {
+	var ends []int
+	if n.min == 0 {
+		ends = []int{pos}
+	}
+
+	current := []int{pos}
+	seen := map[int]bool{}
+	for count := 1; n.max < 0 || count <= n.max; count++ {
+		var next []int
+		for _, end := range current {
+			next = union(next, n.element.match(m, end))
+		}
+
+		if count >= n.min {
+			// once the minimum is reached, a position that was already reached with fewer repetitions
+			// cannot lead to a match that was not already found
+			unseen := next[:0:0]
+			for _, end := range next {
+				if !seen[end] {
+					seen[end] = true
+					unseen = append(unseen, end)
+				}
+			}
+			next = unseen
+			ends = union(ends, next)
+		}
+
+		if len(next) == 0 {
+			break
+		}
+		current = next
+	}
+
+	return ends
+}
+
+func (n ruleRef) match(m *matcher, pos int) []int {
+	key := memoKey{rule: string(n), pos: pos}
+	if ends, ok := m.memo[key]; ok {
+		return ends
+	}
+
+	// a left recursive rule does not match, instead of recursing forever
+	m.memo[key] = nil
+	ends := m.grammar.rules[string(n)].match(m, pos)
+	m.memo[key] = ends
+
+	return ends
+}
+
+func (n literal) match(m *matcher, pos int) []int {
+	end := pos + len(n.value)
+	if end > len(m.input) {
+		return nil
+	}
+
+	candidate := m.input[pos:end]
+	if candidate == n.value || (!n.caseSensitive && strings.EqualFold(candidate, n.value) && isASCII(candidate)) {
+		return []int{end}
+	}
+	return nil
+}
+
+func (n byteRange) match(m *matcher, pos int) []int {
+	if pos < len(m.input) && m.input[pos] >= n.first && m.input[pos] <= n.last {
+		return []int{pos + 1}
+	}
+	return nil
+}
+
+// union returns the sorted union of two sorted sets of positions
+func union(a, b []int) []int {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+
+	merged := append(append(make([]int, 0, len(a)+len(b)), a...), b...)
+	sort.Ints(merged)
+
+	unique := merged[:1]
+	for _, end := range merged[1:] {
+		if end != unique[len(unique)-1] {
+			unique = append(unique, end)
+		}
+	}
+	return unique
+}
+
+// isASCII returns true if s is made of US-ASCII bytes, strings.EqualFold also folds some unicode letters
+// into ASCII letters, ex- the Kelvin sign into k, which ABNF does not
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package did
+
+import (
+	"iter"
+	"strings"
+)
+
+// Segments returns an iterator over the percent decoded segments of the path of a DID URL, ex- the path
+// of did:web:example.com/docs/a%20b yields docs and a b. The segments are split from Path, or are the
+// PathSegments if Path is empty. A segment that is not validly percent encoded is yielded as is.
+func (d *DID) Segments() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		if d.Path != "" {
+			splitSeq(d.Path, '/', ComponentPath, yield)
+			return
+		}
+		for _, segment := range d.PathSegments {
+			if !yield(unescapeOrKeep(segment, ComponentPath)) {
+				return
+			}
+		}
+	}
+}
+
+// IDParts returns an iterator over the percent decoded idstrings of the method-specific-id of a DID, ex- the
+// method-specific-id of did:web:example.com%3A3000:user yields example.com:3000 and user. The idstrings are split
+// from ID, or are the IDStrings if ID is empty. An idstring that is not validly percent encoded is yielded as is.
+func (d *DID) IDParts() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		if d.ID != "" {
+			splitSeq(d.ID, ':', ComponentMethodSpecificID, yield)
+			return
+		}
+		for _, idString := range d.IDStrings {
+			if !yield(unescapeOrKeep(idString, ComponentMethodSpecificID)) {
+				return
+			}
+		}
+	}
+}
+
+// QueryParams returns an iterator over the percent decoded names and values of the `&` separated
+// name=value pairs of the Query of a DID URL, in the order in which they appear, ex- the query of
+// did:example:123?service=files&relativeRef=%2Fa yields service files and relativeRef /a.
+// Empty pairs are skipped, a pair without a `=` has an empty value, and a name or value
+// that is not validly percent encoded is yielded as is. See Parameters to parse the DID parameters.
+func (d *DID) QueryParams() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		query := d.Query
+		for query != "" {
+			pair := query
+			if i := strings.IndexByte(query, '&'); i >= 0 {
+				pair, query = query[:i], query[i+1:]
+			} else {
+				query = ""
+			}
+			if pair == "" {
+				continue
+			}
+
+			name, value := pair, ""
+			if i := strings.IndexByte(pair, '='); i >= 0 {
+				name, value = pair[:i], pair[i+1:]
+			}
+			if !yield(unescapeOrKeep(name, ComponentQuery), unescapeOrKeep(value, ComponentQuery)) {
+				return
+			}
+		}
+	}
+}
+
+// PathUnescaped returns the percent decoded path of a DID URL, without its leading `/`.
+// An encoded `/` is decoded too, use Segments to tell it apart from a segment separator.
+// If the path is not validly percent encoded, it is returned as is.
+func (d *DID) PathUnescaped() string {
+	return unescapeOrKeep(d.pathString(), ComponentPath)
+}
+
+// splitSeq yields the percent decoded parts of s, a part of the given component, that are separated by sep
+func splitSeq(s string, sep byte, component Component, yield func(string) bool) {
+	for {
+		i := strings.IndexByte(s, sep)
+		if i < 0 {
+			yield(unescapeOrKeep(s, component))
+			return
+		}
+		if !yield(unescapeOrKeep(s[:i], component)) {
+			return
+		}
+		s = s[i+1:]
+	}
+}
+
+// unescapeOrKeep decodes the pct-encoded octets in s, or returns s if it is not validly encoded
+func unescapeOrKeep(s string, component Component) string {
+	unescaped, err := unescape(s, component)
+	if err != nil {
+		return s
+	}
+	return unescaped
+}
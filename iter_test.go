@@ -0,0 +1,114 @@
+package did
+
+import (
+	"testing"
+)
+
+func TestSegments(t *testing.T) {
+	tests := []struct {
+		d        *DID
+		expected []string
+	}{
+		{&DID{Method: "web", ID: "example.com"}, nil},
+		{&DID{Method: "web", ID: "example.com", Path: "docs/a%20b"}, []string{"docs", "a b"}},
+		{&DID{Method: "web", ID: "example.com", Path: "a//b%2Fc/"}, []string{"a", "", "b/c", ""}},
+		{&DID{Method: "web", ID: "example.com", PathSegments: []string{"a%41", "b"}}, []string{"aA", "b"}},
+		{&DID{Method: "web", ID: "example.com", Path: "x", PathSegments: []string{"a", "b"}}, []string{"x"}},
+		{&DID{Method: "web", ID: "example.com", Path: "a%2/b%zz"}, []string{"a%2", "b%zz"}},
+		{&DID{PathSegments: []string{""}}, []string{""}},
+	}
+
+	for _, test := range tests {
+		var segments []string
+		for segment := range test.d.Segments() {
+			segments = append(segments, segment)
+		}
+		assert(t, test.expected, segments, "DID: %#v", test.d)
+	}
+
+	// the iterator stops when yield returns false
+	d := &DID{Method: "web", ID: "example.com", Path: "a/b/c"}
+	for segment := range d.Segments() {
+		assert(t, "a", segment)
+		break
+	}
+}
+
+func TestIDParts(t *testing.T) {
+	tests := []struct {
+		d        *DID
+		expected []string
+	}{
+		{&DID{Method: "web"}, nil},
+		{&DID{Method: "web", ID: "example.com%3A3000:user"}, []string{"example.com:3000", "user"}},
+		{&DID{Method: "example", ID: "::a"}, []string{"", "", "a"}},
+		{&DID{Method: "example", IDStrings: []string{"a%2D", "b"}}, []string{"a-", "b"}},
+		{&DID{Method: "example", ID: "x", IDStrings: []string{"a", "b"}}, []string{"x"}},
+		{&DID{Method: "example", ID: "a%G0"}, []string{"a%G0"}},
+	}
+
+	for _, test := range tests {
+		var parts []string
+		for part := range test.d.IDParts() {
+			parts = append(parts, part)
+		}
+		assert(t, test.expected, parts, "DID: %#v", test.d)
+	}
+
+	d, err := Parse("did:example:a:b:c")
+	assert(t, nil, err)
+	for part := range d.IDParts() {
+		assert(t, "a", part)
+		break
+	}
+}
+
+func TestQueryParams(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected [][2]string
+	}{
+		{"", nil},
+		{"service=files&relativeRef=%2Fa", [][2]string{{"service", "files"}, {"relativeRef", "/a"}}},
+		{"&a&&b=&c=1=2&", [][2]string{{"a", ""}, {"b", ""}, {"c", "1=2"}}},
+		{"x=1&x=2", [][2]string{{"x", "1"}, {"x", "2"}}},
+		{"a%3Db=c%26d", [][2]string{{"a=b", "c&d"}}},
+		{"a=%2&b=+", [][2]string{{"a", "%2"}, {"b", "+"}}},
+	}
+
+	for _, test := range tests {
+		d := &DID{Method: "example", ID: "123", Query: test.query}
+		var params [][2]string
+		for name, value := range d.QueryParams() {
+			params = append(params, [2]string{name, value})
+		}
+		assert(t, test.expected, params, "Query: %s", test.query)
+	}
+
+	d := &DID{Method: "example", ID: "123", Query: "a=1&b=2"}
+	for name := range d.QueryParams() {
+		assert(t, "a", name)
+		break
+	}
+}
+
+func TestPathUnescaped(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"did:example:123", ""},
+		{"did:example:123/", ""},
+		{"did:example:123/a%20b/c%2Fd", "a b/c/d"},
+		{"did:example:123/a/b?x#y", "a/b"},
+	}
+
+	for _, test := range tests {
+		d, err := Parse(test.input)
+		assert(t, nil, err, "Input: %s", test.input)
+		assert(t, test.expected, d.PathUnescaped(), "Input: %s", test.input)
+	}
+
+	assert(t, "a/b", (&DID{PathSegments: []string{"a", "b"}}).PathUnescaped())
+	assert(t, "a%zz", (&DID{Path: "a%zz"}).PathUnescaped())
+}
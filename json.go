@@ -0,0 +1,148 @@
+package did
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// MediaTypeJSON is the media type of the JSON representation of a DID document
+// https://www.w3.org/TR/did-core/#application-did-json
+const MediaTypeJSON = "application/did+json"
+
+// ErrInvalidJSON is returned when the JSON representation of a DID document is followed by more data,
+// nests arrays and maps too deep, or is not valid JSON in a way that encoding/json does not report
+var ErrInvalidJSON = errors.New("invalid JSON")
+
+// maxJSONDepth is the number of arrays and maps that may be nested in one another in a JSON representation
+const maxJSONDepth = 256
+
+// Document can be used as a field in JSON documents, it is encoded in the JSON representation of DID Core
+var (
+	_ json.Marshaler   = (*Document)(nil)
+	_ json.Unmarshaler = (*Document)(nil)
+)
+
+// MarshalJSON implements the json.Marshaler interface, it produces the JSON representation of a DID document.
+// A controller, a service type and a service endpoint that have a single value are encoded as that value,
+// and as a set otherwise. References to verification methods are encoded as DID URL strings and embedded
+// methods as maps. The returned error is a *DocumentError if a DID URL cannot be encoded, or if Properties
+// hold a property that is defined by DID Core.
+// https://www.w3.org/TR/did-core/#production-0
+func (doc *Document) MarshalJSON() ([]byte, error) {
+	m, err := doc.toValue()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, it is the same as DecodeJSON with the default options
+func (doc *Document) UnmarshalJSON(data []byte) error {
+	decoded, err := DecodeJSON(data, DecodeOptions{})
+	if err != nil {
+		return err
+	}
+	*doc = *decoded
+	return nil
+}
+
+// DecodeJSON consumes the JSON representation of a DID document. The DID URLs of the document are parsed
+// with Parse, or with ParseReference where DID Core allows a relative DID URL reference, and numbers are
+// decoded as float64 in Properties. The returned error is a *DocumentError with the JSON pointer of the
+// value that is not valid, which wraps the *ParseError of a DID URL, or ErrInvalidType, ErrDuplicateKey
+// or the error of encoding/json if data is not valid JSON.
+// https://www.w3.org/TR/did-core/#consumption-0
+func DecodeJSON(data []byte, opts DecodeOptions) (*Document, error) {
+	v, err := decodeJSONDocument(data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	dc := &decoder{opts: opts}
+	return dc.document(v)
+}
+
+// decodeJSONDocument decodes the JSON of a DID document into a generic value
+func decodeJSONDocument(data []byte, opts DecodeOptions) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	v, err := decodeJSONValue(dec, "", 0, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, documentError("", ErrInvalidJSON, "DID document is followed by more data")
+	}
+
+	return v, nil
+}
+
+// decodeJSONValue decodes the next JSON value of dec, at pointer, into a generic value, the value is nested
+// in depth arrays and maps. encoding/json keeps the last value of a duplicate key, so maps are decoded
+// token by token to find them.
+func decodeJSONValue(dec *json.Decoder, pointer string, depth int, opts DecodeOptions) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, documentError(pointer, err, "DID document is not valid JSON: %v", err)
+	}
+
+	if (token == json.Delim('{') || token == json.Delim('[')) && depth >= maxJSONDepth {
+		return nil, documentError(pointer, ErrInvalidJSON, "arrays and maps are nested too deep")
+	}
+
+	switch token {
+	case json.Delim('{'):
+		m := make(map[string]interface{})
+		for dec.More() {
+			token, err := dec.Token()
+			if err != nil {
+				return nil, documentError(pointer, err, "DID document is not valid JSON: %v", err)
+			}
+			// the keys of a map are always strings
+			key := token.(string)
+			keyPointer := appendPointer(pointer, key)
+
+			if _, ok := m[key]; ok && opts.Strict {
+				return nil, documentError(keyPointer, ErrDuplicateKey, "key %q appears more than once", key)
+			}
+
+			m[key], err = decodeJSONValue(dec, keyPointer, depth+1, opts)
+			if err != nil {
+				return nil, err
+			}
+		}
+		// read the closing `}`
+		if _, err := dec.Token(); err != nil {
+			return nil, documentError(pointer, err, "DID document is not valid JSON: %v", err)
+		}
+		return m, nil
+
+	case json.Delim('['):
+		values := []interface{}{}
+		for dec.More() {
+			value, err := decodeJSONValue(dec, appendPointer(pointer, len(values)), depth+1, opts)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		// read the closing `]`
+		if _, err := dec.Token(); err != nil {
+			return nil, documentError(pointer, err, "DID document is not valid JSON: %v", err)
+		}
+		return values, nil
+
+	default:
+		if _, ok := token.(json.Delim); ok {
+			return nil, documentError(pointer, ErrInvalidJSON, "unexpected %v", token)
+		}
+		// a string, float64, bool or nil
+		return token, nil
+	}
+}
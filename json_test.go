@@ -0,0 +1,201 @@
+package did
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// readDocument returns the contents of a document in testdata/documents
+func readDocument(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/documents/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestDecodeJSON(t *testing.T) {
+	for _, opts := range []DecodeOptions{{}, {Strict: true}} {
+		doc, err := DecodeJSON(readDocument(t, "example.json"), opts)
+		assert(t, nil, err, "Options: %#v", opts)
+
+		assert(t, []interface{}{ContextV1, "https://w3id.org/security/suites/ed25519-2020/v1"}, doc.Context)
+		assert(t, "did:example:123456789abcdefghi", doc.ID.String())
+		assert(t, 1, len(doc.Controller))
+		assert(t, "did:example:bcehfew7h32f32h7af3", doc.Controller[0].String())
+		assert(t, []string{"https://example.com/alice"}, doc.AlsoKnownAs)
+
+		assert(t, 2, len(doc.VerificationMethod))
+		assert(t, "key-1", doc.VerificationMethod[0].ID.Fragment)
+		assert(t, "Ed25519VerificationKey2020", doc.VerificationMethod[0].Type)
+		assert(t, "zH3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV", doc.VerificationMethod[0].PublicKeyMultibase)
+		assert(t, "#key-2", doc.VerificationMethod[1].ID.String())
+		assert(t, "OKP", doc.VerificationMethod[1].PublicKeyJwk["kty"])
+
+		assert(t, 2, len(doc.Authentication))
+		assert(t, "did:example:123456789abcdefghi#key-1", doc.Authentication[0].Reference.String())
+		assert(t, (*VerificationMethod)(nil), doc.Authentication[0].Method)
+		assert(t, (*DID)(nil), doc.Authentication[1].Reference)
+		assert(t, "auth-1", doc.Authentication[1].Method.ID.Fragment)
+
+		methods, err := doc.VerificationMethods(RelationshipAssertionMethod)
+		assert(t, nil, err)
+		assert(t, []*VerificationMethod{&doc.VerificationMethod[1]}, methods)
+
+		assert(t, 2, len(doc.Service))
+		assert(t, []string{"LinkedDomains"}, doc.Service[0].Type)
+		assert(t, []interface{}{"https://bar.example.com"}, doc.Service[0].ServiceEndpoint)
+		assert(t, []string{"DIDCommMessaging", "Other"}, doc.Service[1].Type)
+		assert(t, 2, len(doc.Service[1].ServiceEndpoint))
+		assert(t, map[string]interface{}{"priority": float64(1)}, doc.Service[1].Properties)
+
+		assert(t, map[string]interface{}{"created": "2024-01-01T00:00:00Z"}, doc.Properties)
+	}
+}
+
+func TestDocumentJSONRoundTrip(t *testing.T) {
+	data := readDocument(t, "example.json")
+
+	var doc Document
+	err := json.Unmarshal(data, &doc)
+	assert(t, nil, err)
+
+	encoded, err := json.Marshal(&doc)
+	assert(t, nil, err)
+
+	// the encoded document has the same JSON value as the original
+	var expected, actual interface{}
+	assert(t, nil, json.Unmarshal(data, &expected))
+	assert(t, nil, json.Unmarshal(encoded, &actual))
+	assert(t, expected, actual)
+
+	decoded, err := DecodeJSON(encoded, DecodeOptions{Strict: true})
+	assert(t, nil, err)
+	assert(t, &doc, decoded)
+}
+
+func TestDocumentMarshalJSON(t *testing.T) {
+	id := mustParse(t, "did:example:123")
+
+	t.Run("encodes single values and sets", func(t *testing.T) {
+		doc := &Document{
+			Context:    []interface{}{ContextV1},
+			ID:         id,
+			Controller: []*DID{id, mustParse(t, "did:example:456")},
+			Service: []Service{{ID: "#a", Type: []string{"A"},
+				ServiceEndpoint: []interface{}{map[string]interface{}{"uri": "https://a"}}}},
+		}
+		data, err := json.Marshal(doc)
+		assert(t, nil, err)
+		assert(t, `{"@context":"https://www.w3.org/ns/did/v1","controller":["did:example:123","did:example:456"],`+
+			`"id":"did:example:123","service":[{"id":"#a","serviceEndpoint":{"uri":"https://a"},"type":"A"}]}`,
+			string(data))
+	})
+
+	t.Run("returns error if a property is defined by DID Core", func(t *testing.T) {
+		doc := &Document{ID: id, Service: []Service{{ID: "#a", Properties: map[string]interface{}{"type": "A"}}}}
+		_, err := json.Marshal(doc)
+
+		var de *DocumentError
+		assert(t, true, errors.As(err, &de))
+		assert(t, "/service/0/type", de.Pointer)
+		assert(t, true, errors.Is(err, ErrReservedProperty))
+	})
+
+	t.Run("returns error if a DID URL cannot be encoded", func(t *testing.T) {
+		doc := &Document{ID: id, Authentication: []RelationshipEntry{{Reference: &DID{ID: "123"}}}}
+		_, err := doc.MarshalJSON()
+
+		var de *DocumentError
+		assert(t, true, errors.As(err, &de))
+		assert(t, "/authentication/0", de.Pointer)
+	})
+
+	t.Run("returns error if a relationship entry is empty", func(t *testing.T) {
+		doc := &Document{ID: id, KeyAgreement: []RelationshipEntry{{}}}
+		_, err := doc.MarshalJSON()
+
+		var de *DocumentError
+		assert(t, true, errors.As(err, &de))
+		assert(t, "/keyAgreement/0", de.Pointer)
+	})
+}
+
+func TestDecodeJSONErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		strict  bool
+		pointer string
+		err     error
+	}{
+		{`[]`, false, "", ErrInvalidType},
+		{`{"id": "did:example:123"} {}`, false, "", ErrInvalidJSON},
+		{`{"id": 123}`, false, "/id", ErrInvalidType},
+		{`{"id": "did:example:1^3"}`, false, "/id", ErrInvalidCharacter},
+		{`{"id": "#key-12345"}`, false, "/id", ErrInvalidScheme},
+		{`{"controller": ["did:example:123", "#a"]}`, false, "/controller/1", ErrTooShort},
+		{`{"controller": {"id": "did:example:123"}}`, false, "/controller", ErrInvalidType},
+		{`{"alsoKnownAs": "https://example.com"}`, false, "/alsoKnownAs", ErrInvalidType},
+		{`{"verificationMethod": [{"id": "did:example:123#a", "type": ["A"]}]}`, false,
+			"/verificationMethod/0/type", ErrInvalidType},
+		{`{"verificationMethod": [{"id": "//a"}]}`, false, "/verificationMethod/0/id", ErrInvalidReference},
+		{`{"verificationMethod": [{"publicKeyJwk": "x"}]}`, false, "/verificationMethod/0/publicKeyJwk",
+			ErrInvalidType},
+		{`{"verificationMethod": {"id": "#a"}}`, false, "/verificationMethod", ErrInvalidType},
+		{`{"authentication": ["#a", 1]}`, false, "/authentication/1", ErrInvalidType},
+		{`{"keyAgreement": [{"controller": "#controller"}]}`, false, "/keyAgreement/0/controller", ErrInvalidScheme},
+		{`{"service": [{"serviceEndpoint": [1]}]}`, false, "/service/0/serviceEndpoint/0", ErrInvalidType},
+		{`{"service": [{"type": ["A", null]}]}`, false, "/service/0/type/1", ErrInvalidType},
+		{`{"a/b~c": {"x": 1, "x": 2}}`, true, "/a~1b~0c/x", ErrDuplicateKey},
+		{`{"id": "did:example:1", "id": "did:example:2"}`, true, "/id", ErrDuplicateKey},
+		{`{"service": [{"id": "#a", "id": "#b"}]}`, true, "/service/0/id", ErrDuplicateKey},
+		{`{"id": null}`, true, "/id", ErrInvalidType},
+		{`{"verificationMethod": [{"type": null}]}`, true, "/verificationMethod/0/type", ErrInvalidType},
+	}
+
+	for _, test := range tests {
+		_, err := DecodeJSON([]byte(test.input), DecodeOptions{Strict: test.strict})
+
+		var de *DocumentError
+		assert(t, true, errors.As(err, &de), "Input: %s", test.input)
+		assert(t, test.pointer, de.Pointer, "Input: %s", test.input)
+		assert(t, true, errors.Is(err, test.err), "Input: %s Error: %v", test.input, err)
+	}
+
+	// deeply nested arrays and maps are rejected before they exhaust the stack
+	for _, depth := range []int{maxJSONDepth, 100000} {
+		input := `{"x": ` + strings.Repeat(`[`, depth) + strings.Repeat(`]`, depth) + `}`
+		_, err := DecodeJSON([]byte(input), DecodeOptions{})
+		var de *DocumentError
+		assert(t, true, errors.As(err, &de), "Depth: %d", depth)
+		assert(t, true, errors.Is(err, ErrInvalidJSON), "Depth: %d Error: %v", depth, err)
+		assert(t, "/x"+strings.Repeat("/0", maxJSONDepth-1), de.Pointer, "Depth: %d", depth)
+	}
+	input := `{"x": ` + strings.Repeat(`[`, maxJSONDepth-1) + strings.Repeat(`]`, maxJSONDepth-1) + `}`
+	_, err := DecodeJSON([]byte(input), DecodeOptions{})
+	assert(t, nil, err)
+
+	// the error of encoding/json is wrapped if the input is not valid JSON
+	for _, input := range []string{``, `{`, `{"id": }`, `{"a": [1,}`} {
+		_, err := DecodeJSON([]byte(input), DecodeOptions{})
+		var de *DocumentError
+		assert(t, true, errors.As(err, &de), "Input: %s", input)
+	}
+}
+
+func TestDecodeJSONLenient(t *testing.T) {
+	doc, err := DecodeJSON([]byte(`{"id": "did:example:1", "id": "did:example:2", "controller": null,
+		"verificationMethod": [{"id": "#a", "type": null}], "x": null}`), DecodeOptions{})
+	assert(t, nil, err)
+	assert(t, "did:example:2", doc.ID.String())
+	assert(t, []*DID(nil), doc.Controller)
+	assert(t, "", doc.VerificationMethod[0].Type)
+	assert(t, map[string]interface{}{"x": nil}, doc.Properties)
+
+	_, err = DecodeJSON([]byte(`{"x": null}`), DecodeOptions{Strict: true})
+	assert(t, nil, err)
+}
@@ -0,0 +1,312 @@
+package did
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// MediaTypeJSONLD is the media type of the JSON-LD representation of a DID document
+// https://www.w3.org/TR/did-core/#application-did-ld-json
+const MediaTypeJSONLD = "application/did+ld+json"
+
+// maxContextDepth is the number of remote contexts that may be loaded from one another
+const maxContextDepth = 16
+
+var (
+	// ErrInvalidContext is returned when the @context of a JSON-LD DID document is missing, does not begin
+	// with ContextV1, or is not a valid JSON-LD context
+	ErrInvalidContext = errors.New("invalid JSON-LD context")
+
+	// ErrUndefinedTerm is returned when a property or a type of a JSON-LD DID document is not defined
+	// by its @context
+	ErrUndefinedTerm = errors.New("term is not defined by the JSON-LD context")
+)
+
+// MarshalJSONLD produces the JSON-LD representation of a DID document, which is its JSON representation
+// with an @context. The first context must be ContextV1, and every property and type of the document must
+// be defined by its contexts, which are loaded with loader, or DefaultLoader if loader is nil.
+// The returned error is a *DocumentError that wraps ErrInvalidContext or ErrUndefinedTerm,
+// or the error of loader if a context cannot be loaded.
+// https://www.w3.org/TR/did-core/#production-1
+func (doc *Document) MarshalJSONLD(loader DocumentLoader) ([]byte, error) {
+	m, err := doc.toValue()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkJSONLD(m, loader); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(m)
+}
+
+// DecodeJSONLD consumes the JSON-LD representation of a DID document, it is the same as DecodeJSON,
+// and checks the @context of the document like MarshalJSONLD does.
+// https://www.w3.org/TR/did-core/#consumption-1
+func DecodeJSONLD(data []byte, opts DecodeOptions, loader DocumentLoader) (*Document, error) {
+	v, err := decodeJSONDocument(data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if m, ok := v.(map[string]interface{}); ok {
+		if err := checkJSONLD(m, loader); err != nil {
+			return nil, err
+		}
+	}
+
+	dc := &decoder{opts: opts}
+	return dc.document(v)
+}
+
+// checkJSONLD checks the @context of the generic value of a DID document, and that its terms are defined
+func checkJSONLD(m map[string]interface{}, loader DocumentLoader) error {
+	context, ok := m["@context"]
+	if !ok {
+		return documentError("", ErrInvalidContext, "JSON-LD DID document does not have an @context")
+	}
+
+	first, pointer := context, "/@context"
+	if contexts, ok := context.([]interface{}); ok {
+		first, pointer = nil, "/@context/0"
+		if len(contexts) > 0 {
+			first = contexts[0]
+		}
+	}
+	if first != ContextV1 {
+		return documentError(pointer, ErrInvalidContext, "first JSON-LD context must be %s", ContextV1)
+	}
+
+	if loader == nil {
+		loader = DefaultLoader
+	}
+	checker := &termChecker{loader: loader}
+
+	return checker.checkNode(&activeContext{terms: map[string]termDefinition{}}, m, "")
+}
+
+// An activeContext holds the terms that are defined where a node of a JSON-LD document is checked
+// https://www.w3.org/TR/json-ld11-api/#context-processing-algorithm
+type activeContext struct {
+	terms map[string]termDefinition
+
+	// a context with a @vocab defines every term
+	vocab bool
+}
+
+// A termDefinition is the definition of a term in a JSON-LD context
+type termDefinition struct {
+	// id is the IRI or the keyword that the term maps to, ex- @type
+	id string
+
+	// typ is the type of the values of the term, ex- @id or @json
+	typ string
+
+	// context is the scoped context of the term, if hasContext is true
+	context    interface{}
+	hasContext bool
+}
+
+// defines returns true if a term is defined in the context, or if it is a compact or an absolute IRI
+func (c *activeContext) defines(term string) bool {
+	if _, ok := c.terms[term]; ok {
+		return true
+	}
+	return c.vocab || strings.Contains(term, ":")
+}
+
+// termChecker checks that the terms of a JSON-LD document are defined by its contexts
+type termChecker struct {
+	loader DocumentLoader
+}
+
+// process returns the active context that results from applying a local context to ctx, the local context
+// is at pointer in the document, and remote holds the URLs of the contexts that are being loaded
+func (tc *termChecker) process(ctx *activeContext, local interface{}, pointer string, remote []string) (*activeContext, error) {
+	switch local := local.(type) {
+	case nil:
+		// a null context removes all the terms
+		return &activeContext{terms: map[string]termDefinition{}}, nil
+
+	case []interface{}:
+		for i, context := range local {
+			p := pointer
+			if len(remote) == 0 {
+				p = appendPointer(pointer, i)
+			}
+			var err error
+			if ctx, err = tc.process(ctx, context, p, remote); err != nil {
+				return nil, err
+			}
+		}
+		return ctx, nil
+
+	case string:
+		for _, url := range remote {
+			if url == local {
+				return nil, documentError(pointer, ErrInvalidContext, "JSON-LD context %s loads itself", local)
+			}
+		}
+		if len(remote) >= maxContextDepth {
+			return nil, documentError(pointer, ErrInvalidContext, "JSON-LD contexts are nested too deep at %s", local)
+		}
+
+		document, err := tc.loader.LoadDocument(local)
+		if err != nil {
+			return nil, documentError(pointer, err, "JSON-LD context %s cannot be loaded: %v", local, err)
+		}
+		m, ok := document.Document.(map[string]interface{})
+		if !ok {
+			return nil, documentError(pointer, ErrInvalidContext, "JSON-LD context %s is not a map", local)
+		}
+		return tc.process(ctx, m["@context"], pointer, append(remote[:len(remote):len(remote)], local))
+
+	case map[string]interface{}:
+		result := &activeContext{terms: make(map[string]termDefinition, len(ctx.terms)+len(local)), vocab: ctx.vocab}
+		for term, definition := range ctx.terms {
+			result.terms[term] = definition
+		}
+
+		for term, definition := range local {
+			if term == "@vocab" {
+				result.vocab = definition != nil
+				continue
+			}
+			if strings.HasPrefix(term, "@") {
+				// the other keywords, like @version and @protected, do not define terms
+				continue
+			}
+
+			switch definition := definition.(type) {
+			case nil:
+				delete(result.terms, term)
+			case string:
+				result.terms[term] = termDefinition{id: definition}
+			case map[string]interface{}:
+				d := termDefinition{}
+				d.id, _ = definition["@id"].(string)
+				d.typ, _ = definition["@type"].(string)
+				d.context, d.hasContext = definition["@context"]
+				result.terms[term] = d
+			default:
+				p := pointer
+				if len(remote) == 0 {
+					p = appendPointer(pointer, term)
+				}
+				return nil, documentError(p, ErrInvalidContext, "definition of term %q is not a string or a map", term)
+			}
+		}
+		return result, nil
+
+	default:
+		return nil, documentError(pointer, ErrInvalidContext, "JSON-LD context must be a string, a map or a set")
+	}
+}
+
+// checkNode checks the terms of a node, a map of the document at pointer, in the active context ctx
+func (tc *termChecker) checkNode(ctx *activeContext, node map[string]interface{}, pointer string) error {
+	var err error
+
+	// an embedded context applies to the node and to the nodes in it
+	if local, ok := node["@context"]; ok {
+		if ctx, err = tc.process(ctx, local, appendPointer(pointer, "@context"), nil); err != nil {
+			return err
+		}
+	}
+	propagated := ctx
+
+	keys := sortedKeys(node)
+
+	// the types of the node must be defined, their scoped contexts apply to the node but not to the nodes in it
+	for _, key := range keys {
+		if key != "@type" && ctx.terms[key].id != "@type" {
+			continue
+		}
+
+		types, ok := node[key].([]interface{})
+		if !ok {
+			types = []interface{}{node[key]}
+		}
+		names := make([]string, 0, len(types))
+		for i, t := range types {
+			name, ok := t.(string)
+			if !ok {
+				return documentError(appendPointer(pointer, key), ErrInvalidType, "type must be a string")
+			}
+			if !propagated.defines(name) {
+				typePointer := appendPointer(pointer, key)
+				if _, isSet := node[key].([]interface{}); isSet {
+					typePointer = appendPointer(typePointer, i)
+				}
+				return documentError(typePointer, ErrUndefinedTerm, "type %q is not defined by the JSON-LD context", name)
+			}
+			names = append(names, name)
+		}
+
+		// type scoped contexts are applied in the lexicographical order of the types
+		sort.Strings(names)
+		for _, name := range names {
+			if definition := propagated.terms[name]; definition.hasContext {
+				if ctx, err = tc.process(ctx, definition.context, appendPointer(pointer, key), nil); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, key := range keys {
+		value := node[key]
+		keyPointer := appendPointer(pointer, key)
+
+		if strings.HasPrefix(key, "@") {
+			if key == "@graph" || key == "@included" {
+				if err := tc.checkValue(propagated, value, keyPointer); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if !ctx.defines(key) {
+			return documentError(keyPointer, ErrUndefinedTerm, "property %q is not defined by the JSON-LD context", key)
+		}
+
+		definition := ctx.terms[key]
+		if definition.id == "@type" || definition.id == "@id" || definition.typ == "@json" {
+			// the values of a type are checked above, the values of an id and of a JSON literal are not terms
+			continue
+		}
+
+		// a property scoped context applies to the nodes in the value of the property
+		valueCtx := propagated
+		if definition.hasContext {
+			if valueCtx, err = tc.process(valueCtx, definition.context, keyPointer, nil); err != nil {
+				return err
+			}
+		}
+
+		if err := tc.checkValue(valueCtx, value, keyPointer); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkValue checks the terms of the nodes in a value of the document at pointer
+func (tc *termChecker) checkValue(ctx *activeContext, value interface{}, pointer string) error {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return tc.checkNode(ctx, value, pointer)
+	case []interface{}:
+		for i, v := range value {
+			if err := tc.checkValue(ctx, v, appendPointer(pointer, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
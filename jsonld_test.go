@@ -0,0 +1,133 @@
+package did
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestDecodeJSONLD(t *testing.T) {
+	data := readDocument(t, "example-ld.json")
+
+	doc, err := DecodeJSONLD(data, DecodeOptions{Strict: true}, nil)
+	assert(t, nil, err)
+	assert(t, 5, len(doc.Context))
+	assert(t, "did:example:123456789abcdefghi", doc.ID.String())
+	assert(t, 2, len(doc.VerificationMethod))
+	assert(t, "Multikey", doc.Authentication[1].Method.Type)
+
+	encoded, err := doc.MarshalJSONLD(nil)
+	assert(t, nil, err)
+
+	var expected, actual interface{}
+	assert(t, nil, json.Unmarshal(data, &expected))
+	assert(t, nil, json.Unmarshal(encoded, &actual))
+	assert(t, expected, actual)
+
+	// the JSON representation of the same document is the same
+	jsonData, err := doc.MarshalJSON()
+	assert(t, nil, err)
+	assert(t, string(jsonData), string(encoded))
+}
+
+func TestDecodeJSONLDErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		pointer string
+		err     error
+	}{
+		{`{"id": "did:example:123"}`, "", ErrInvalidContext},
+		{`{"@context": [], "id": "did:example:123"}`, "/@context/0", ErrInvalidContext},
+		{`{"@context": "https://w3id.org/security/v2"}`, "/@context", ErrInvalidContext},
+		{`{"@context": ["https://w3id.org/security/v2", "https://www.w3.org/ns/did/v1"]}`, "/@context/0",
+			ErrInvalidContext},
+		{`{"@context": ["https://www.w3.org/ns/did/v1", "https://example.com/v1"]}`, "/@context/1",
+			ErrContextNotFound},
+		{`{"@context": ["https://www.w3.org/ns/did/v1", 1]}`, "/@context/1", ErrInvalidContext},
+		{`{"@context": ["https://www.w3.org/ns/did/v1", {"a": 1}]}`, "/@context/1/a", ErrInvalidContext},
+		{`{"@context": "https://www.w3.org/ns/did/v1", "created": "2024-01-01T00:00:00Z"}`, "/created",
+			ErrUndefinedTerm},
+		{`{"@context": "https://www.w3.org/ns/did/v1", "verificationMethod": [{"id": "did:example:1#a",
+			"type": "Ed25519VerificationKey2020"}]}`, "/verificationMethod/0/type", ErrUndefinedTerm},
+		{`{"@context": ["https://www.w3.org/ns/did/v1", "https://w3id.org/security/suites/jws-2020/v1"],
+			"verificationMethod": [{"id": "did:example:1#a", "type": "JsonWebKey2020", "publicKeyMultibase": "z6Mk"}]}`,
+			"/verificationMethod/0/publicKeyMultibase", ErrUndefinedTerm},
+		{`{"@context": "https://www.w3.org/ns/did/v1", "service": [{"id": "#a", "type": ["A"],
+			"serviceEndpoint": "https://a"}]}`, "/service/0/type/0", ErrUndefinedTerm},
+		{`{"@context": "https://www.w3.org/ns/did/v1", "service": [{"id": "#a",
+			"serviceEndpoint": {"uri": "https://a"}}]}`, "/service/0/serviceEndpoint/uri", ErrUndefinedTerm},
+		{`{"@context": ["https://www.w3.org/ns/did/v1", {"LinkedDomains": "https://a#LinkedDomains"}],
+			"service": [{"id": "#a", "type": "LinkedDomains", "serviceEndpoint": "https://a", "priority": 1}]}`,
+			"/service/0/priority", ErrUndefinedTerm},
+	}
+
+	for _, test := range tests {
+		_, err := DecodeJSONLD([]byte(test.input), DecodeOptions{}, nil)
+
+		var de *DocumentError
+		assert(t, true, errors.As(err, &de), "Input: %s Error: %v", test.input, err)
+		assert(t, test.pointer, de.Pointer, "Input: %s Error: %v", test.input, err)
+		assert(t, true, errors.Is(err, test.err), "Input: %s Error: %v", test.input, err)
+	}
+}
+
+func TestJSONLDTerms(t *testing.T) {
+	tests := []string{
+		// security v1 is loaded through security v2
+		`{"@context": ["https://www.w3.org/ns/did/v1", "https://w3id.org/security/v2"],
+			"created": "2024-01-01T00:00:00Z", "publicKey": "did:example:1#a"}`,
+		// absolute and compact IRIs do not need to be defined
+		`{"@context": ["https://www.w3.org/ns/did/v1", {"ex": "https://example.com/#"}],
+			"https://example.com/#a": 1, "ex:b": {"c:d": 2}}`,
+		// a @vocab defines every term
+		`{"@context": ["https://www.w3.org/ns/did/v1", {"@vocab": "https://example.com/#"}], "anything": {"x": 1}}`,
+		// an embedded context applies to the node it is in and to the nodes in it
+		`{"@context": "https://www.w3.org/ns/did/v1", "service": [{"@context": {"Messaging": "https://a#Messaging",
+			"accept": "https://a#accept"}, "id": "#a", "type": "Messaging", "serviceEndpoint": {"accept": ["x"]}}]}`,
+		// the value of a JSON literal is not checked
+		`{"@context": ["https://www.w3.org/ns/did/v1", "https://w3id.org/security/suites/jws-2020/v1"],
+			"verificationMethod": [{"id": "#a", "type": "JsonWebKey2020", "publicKeyJwk": {"anything": 1}}]}`,
+	}
+
+	for _, input := range tests {
+		_, err := DecodeJSONLD([]byte(input), DecodeOptions{}, nil)
+		assert(t, nil, err, "Input: %s", input)
+	}
+
+	// a type scoped context does not apply to the nodes in the node
+	input := `{"@context": ["https://www.w3.org/ns/did/v1", "https://w3id.org/security/multikey/v1"],
+		"verificationMethod": [{"id": "#a", "type": "Multikey", "controller": "did:example:1",
+		"publicKeyMultibase": "z6Mk"}], "authentication": [{"id": "#b", "publicKeyMultibase": "z6Mk"}]}`
+	_, err := DecodeJSONLD([]byte(input), DecodeOptions{}, nil)
+	var de *DocumentError
+	assert(t, true, errors.As(err, &de))
+	assert(t, "/authentication/0/publicKeyMultibase", de.Pointer)
+
+	// a context that loads itself is not valid
+	var loader OfflineLoader
+	assert(t, nil, loader.AddContext("https://example.com/loop", []byte(`{"@context": "https://example.com/loop"}`)))
+	_, err = DecodeJSONLD([]byte(`{"@context": ["https://www.w3.org/ns/did/v1", "https://example.com/loop"]}`),
+		DecodeOptions{}, &loader)
+	assert(t, true, errors.Is(err, ErrInvalidContext))
+}
+
+func TestMarshalJSONLD(t *testing.T) {
+	doc := &Document{ID: mustParse(t, "did:example:123")}
+	_, err := doc.MarshalJSONLD(nil)
+	assert(t, true, errors.Is(err, ErrInvalidContext))
+
+	doc.Context = []interface{}{ContextV1}
+	data, err := doc.MarshalJSONLD(nil)
+	assert(t, nil, err)
+	assert(t, `{"@context":"https://www.w3.org/ns/did/v1","id":"did:example:123"}`, string(data))
+
+	doc.Properties = map[string]interface{}{"name": "Alice"}
+	_, err = doc.MarshalJSONLD(nil)
+	assert(t, true, errors.Is(err, ErrUndefinedTerm))
+
+	var loader OfflineLoader
+	assert(t, nil, loader.AddContext("https://example.com/v1", []byte(`{"@context": {"name": "https://schema.org/name"}}`)))
+	doc.Context = append(doc.Context, "https://example.com/v1")
+	_, err = doc.MarshalJSONLD(&loader)
+	assert(t, nil, err)
+}
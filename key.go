@@ -0,0 +1,195 @@
+package did
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The multicodec codes of the public key types that are used by did:key
+// https://github.com/multiformats/multicodec/blob/master/table.csv
+const (
+	CodecSecp256k1Pub    uint64 = 0xe7
+	CodecBLS12381G1Pub   uint64 = 0xea
+	CodecBLS12381G2Pub   uint64 = 0xeb
+	CodecX25519Pub       uint64 = 0xec
+	CodecEd25519Pub      uint64 = 0xed
+	CodecBLS12381G1G2Pub uint64 = 0xee
+	CodecP256Pub         uint64 = 0x1200
+	CodecP384Pub         uint64 = 0x1201
+	CodecP521Pub         uint64 = 0x1202
+	CodecRSAPub          uint64 = 0x1205
+)
+
+// the length in bytes of the public keys of each codec, keys of codecs that are not listed may have any length
+var keyLengths = map[uint64]int{
+	CodecSecp256k1Pub:  33, // compressed
+	CodecBLS12381G1Pub: 48,
+	CodecBLS12381G2Pub: 96,
+	CodecX25519Pub:     32,
+	CodecEd25519Pub:    32,
+	CodecP256Pub:       33, // compressed
+	CodecP384Pub:       49, // compressed
+}
+
+// A KeyID is the decoded method-specific-id of a did:key DID
+// https://w3c-ccg.github.io/did-method-key/
+type KeyID struct {
+	// Codec is the multicodec code of the public key type, ex- CodecEd25519Pub
+	Codec uint64
+
+	// PublicKey is the raw public key, without the multicodec prefix
+	PublicKey []byte
+}
+
+// ParseKeyID decodes the method-specific-id of a did:key DID, which is a multibase base58btc encoded
+// (`z` prefixed) multicodec public key. The length of the public key is checked for the known key types.
+// from the grammar:
+//   did-key-format := did:key:<mb-value>
+//   mb-value       := z[a-km-zA-HJ-NP-Z1-9]+
+func ParseKeyID(id string) (*KeyID, error) {
+	if len(id) < 2 || id[0] != 'z' {
+		return nil, errors.New("did:key method-specific-id must be a multibase base58btc value, beginning with z")
+	}
+
+	decoded, err := decodeBase58(id[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	codec, n := decodeUvarint(decoded)
+	if n <= 0 {
+		return nil, errors.New("did:key value does not begin with a multicodec varint")
+	}
+
+	key := decoded[n:]
+	if len(key) == 0 {
+		return nil, errors.New("did:key public key is empty")
+	}
+	if length, ok := keyLengths[codec]; ok && len(key) != length {
+		return nil, fmt.Errorf("did:key public key of codec 0x%x must be %d bytes long, got %d", codec, length, len(key))
+	}
+
+	return &KeyID{Codec: codec, PublicKey: key}, nil
+}
+
+// String encodes a KeyID as the method-specific-id of a did:key DID
+func (k *KeyID) String() string {
+	buf := appendUvarint(nil, k.Codec)
+	buf = append(buf, k.PublicKey...)
+	return "z" + encodeBase58(buf)
+}
+
+func init() {
+	RegisterMethod("key", MethodSyntaxFunc(func(id string) (interface{}, error) {
+		return ParseKeyID(id)
+	}))
+}
+
+// the base58 alphabet of bitcoin, which is used by multibase base58btc
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Index maps a byte to its value in base58Alphabet, or to -1 if the byte is not in the alphabet
+var base58Index = func() [256]int8 {
+	var index [256]int8
+	for i := range index {
+		index[i] = -1
+	}
+	for i := 0; i < len(base58Alphabet); i++ {
+		index[base58Alphabet[i]] = int8(i)
+	}
+	return index
+}()
+
+// decodeBase58 decodes a base58btc string, each leading `1` is decoded as a leading zero byte
+func decodeBase58(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros = zeros + 1
+	}
+
+	// the decoded value is accumulated as a big endian number in base 256,
+	// log(58) / log(256) is less than 733/1000
+	out := make([]byte, (len(s)-zeros)*733/1000+1)
+	length := 0
+
+	for i := zeros; i < len(s); i++ {
+		value := base58Index[s[i]]
+		if value < 0 {
+			return nil, fmt.Errorf("did:key value has a character that is not base58btc - %c", s[i])
+		}
+
+		carry := int(value)
+		for j := len(out) - 1; j >= len(out)-length || carry != 0; j-- {
+			carry = carry + 58*int(out[j])
+			out[j] = byte(carry)
+			carry = carry >> 8
+			if len(out)-j > length {
+				length = len(out) - j
+			}
+		}
+	}
+
+	return append(make([]byte, zeros), out[len(out)-length:]...), nil
+}
+
+// encodeBase58 encodes b as a base58btc string, each leading zero byte is encoded as a `1`
+func encodeBase58(b []byte) string {
+	zeros := 0
+	for zeros < len(b) && b[zeros] == 0 {
+		zeros = zeros + 1
+	}
+
+	// the encoded value is accumulated as a big endian number in base 58,
+	// log(256) / log(58) is less than 138/100
+	out := make([]byte, (len(b)-zeros)*138/100+1)
+	length := 0
+
+	for i := zeros; i < len(b); i++ {
+		carry := int(b[i])
+		for j := len(out) - 1; j >= len(out)-length || carry != 0; j-- {
+			carry = carry + 256*int(out[j])
+			out[j] = byte(carry % 58)
+			carry = carry / 58
+			if len(out)-j > length {
+				length = len(out) - j
+			}
+		}
+	}
+
+	encoded := make([]byte, zeros+length)
+	for i := 0; i < zeros; i++ {
+		encoded[i] = '1'
+	}
+	for i, digit := range out[len(out)-length:] {
+		encoded[zeros+i] = base58Alphabet[digit]
+	}
+
+	return string(encoded)
+}
+
+// decodeUvarint decodes an unsigned varint as used by multicodec, it returns the value and the number of
+// bytes read, or n <= 0 if b does not begin with a varint of at most 9 bytes
+// https://github.com/multiformats/unsigned-varint
+func decodeUvarint(b []byte) (uint64, int) {
+	var value uint64
+	for i := 0; i < len(b) && i < 9; i++ {
+		value = value | uint64(b[i]&0x7f)<<(7*uint(i))
+		if b[i]&0x80 == 0 {
+			if b[i] == 0 && i > 0 {
+				// the varint is not minimally encoded
+				return 0, -1
+			}
+			return value, i + 1
+		}
+	}
+	return 0, 0
+}
+
+// appendUvarint appends the unsigned varint encoding of value to b
+func appendUvarint(b []byte, value uint64) []byte {
+	for value >= 0x80 {
+		b = append(b, byte(value)|0x80)
+		value = value >> 7
+	}
+	return append(b, byte(value))
+}
@@ -0,0 +1,92 @@
+package did
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestParseKeyID(t *testing.T) {
+	t.Run("decodes an ed25519 key", func(t *testing.T) {
+		d, err := ParseWithOptions("did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK",
+			ParseOptions{ValidateMethodSpecificID: true})
+		assert(t, nil, err)
+
+		value, err := d.MethodSpecific()
+		assert(t, nil, err)
+
+		key := value.(*KeyID)
+		assert(t, CodecEd25519Pub, key.Codec)
+		assert(t, "2e6fcce36701dc791488e0d0b1745cc1e33a4c1c9fcc41c63bd343dbbe0970e6", hex.EncodeToString(key.PublicKey))
+		assert(t, d.ID, key.String())
+	})
+
+	t.Run("decodes a key of an unknown codec", func(t *testing.T) {
+		key := &KeyID{Codec: 0x300000, PublicKey: []byte{0, 1, 2}}
+		decoded, err := ParseKeyID(key.String())
+		assert(t, nil, err)
+		assert(t, key, decoded)
+	})
+
+	invalid := []string{
+		"",
+		"z",
+		"6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK",
+		"z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2do0",
+		"z" + encodeBase58(append([]byte{0xed, 0x01}, make([]byte, 31)...)),
+		"z" + encodeBase58([]byte{0xed}),
+		"z" + encodeBase58([]byte{0x80}),
+	}
+
+	for _, id := range invalid {
+		_, err := ParseKeyID(id)
+		assert(t, false, err == nil, "Input: %s", id)
+
+		_, err = ParseWithOptions("did:key:"+id, ParseOptions{ValidateMethodSpecificID: true})
+		assert(t, false, err == nil, "Input: %s", id)
+	}
+
+	_, err := ParseWithOptions("did:key:hello", ParseOptions{ValidateMethodSpecificID: true})
+	assert(t, true, errors.Is(err, ErrInvalidMethodSpecificID))
+}
+
+func Test_base58(t *testing.T) {
+	tests := []struct {
+		decoded []byte
+		encoded string
+	}{
+		{[]byte{}, ""},
+		{[]byte{0}, "1"},
+		{[]byte{0, 0, 1}, "112"},
+		{[]byte("hello world"), "StV1DL6CwTryKyV"},
+		{[]byte{0xff, 0xff}, "LUv"},
+	}
+
+	for _, test := range tests {
+		assert(t, test.encoded, encodeBase58(test.decoded))
+		decoded, err := decodeBase58(test.encoded)
+		assert(t, nil, err)
+		assert(t, true, bytes.Equal(test.decoded, decoded), "Input: %s", test.encoded)
+	}
+
+	_, err := decodeBase58("0OIl")
+	assert(t, false, err == nil)
+}
+
+func Test_uvarint(t *testing.T) {
+	for _, value := range []uint64{0, 1, 0x7f, 0x80, 0xed, 0x1200, 1<<63 - 1} {
+		encoded := appendUvarint(nil, value)
+		decoded, n := decodeUvarint(encoded)
+		assert(t, value, decoded)
+		assert(t, len(encoded), n)
+	}
+
+	// not minimally encoded
+	_, n := decodeUvarint([]byte{0x81, 0x00})
+	assert(t, true, n <= 0)
+
+	// truncated
+	_, n = decodeUvarint([]byte{0x81})
+	assert(t, true, n <= 0)
+}
@@ -0,0 +1,114 @@
+package did
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrInvalidMethodSpecificID is returned when a method-specific-id does not conform to the syntax
+	// registered for its method
+	ErrInvalidMethodSpecificID = errors.New("method-specific-id is not valid for its method")
+
+	// ErrMethodNotRegistered is returned by MethodSpecific when no syntax is registered for the method of a DID
+	ErrMethodNotRegistered = errors.New("method is not registered")
+)
+
+// MethodSyntax validates and decodes the method-specific-id of a DID method.
+// https://www.w3.org/TR/did-core/#method-syntax
+type MethodSyntax interface {
+	// Parse returns the decoded value of a method-specific-id, or an error if it is not valid.
+	// id is the method-specific-id as it appears in the DID, it may contain pct-encoded octets.
+	Parse(id string) (interface{}, error)
+}
+
+// The MethodSyntaxFunc type is an adapter to allow the use of ordinary functions as a MethodSyntax
+type MethodSyntaxFunc func(id string) (interface{}, error)
+
+// Parse calls f(id)
+func (f MethodSyntaxFunc) Parse(id string) (interface{}, error) {
+	return f(id)
+}
+
+// the registry of method syntaxes, guarded by methodsMu
+var (
+	methodsMu sync.RWMutex
+	methods   = make(map[string]MethodSyntax)
+)
+
+// RegisterMethod makes the syntax of a DID method available to Parse and MethodSpecific.
+// The syntaxes of did:key and did:web are registered by this package.
+// If RegisterMethod is called twice with the same name, if name is not a valid method name or if syntax is nil,
+// it panics.
+func RegisterMethod(name string, syntax MethodSyntax) {
+	methodsMu.Lock()
+	defer methodsMu.Unlock()
+
+	if syntax == nil {
+		panic("did: RegisterMethod syntax is nil")
+	}
+	if err := checkMethod(name); err != nil {
+		panic("did: RegisterMethod name is not a valid method name " + name)
+	}
+	if _, dup := methods[name]; dup {
+		panic("did: RegisterMethod called twice for method " + name)
+	}
+
+	methods[name] = syntax
+}
+
+// Methods returns a sorted list of the names of the registered methods
+func Methods() []string {
+	methodsMu.RLock()
+	defer methodsMu.RUnlock()
+
+	names := make([]string, 0, len(methods))
+	for name := range methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// lookupMethod returns the syntax registered for a method
+func lookupMethod(name string) (MethodSyntax, bool) {
+	methodsMu.RLock()
+	defer methodsMu.RUnlock()
+
+	syntax, ok := methods[name]
+	return syntax, ok
+}
+
+// MethodSpecific returns the decoded value of the method-specific-id of a DID, using the syntax
+// registered for its method. The type of the value depends on the method, ex- *KeyID for did:key
+// and *WebID for did:web. It returns a *ParseError that wraps ErrInvalidMethodSpecificID if the
+// method-specific-id is not valid, or ErrMethodNotRegistered if no syntax is registered for the method.
+func (d *DID) MethodSpecific() (interface{}, error) {
+	syntax, ok := lookupMethod(d.Method)
+	if !ok {
+		return nil, fmt.Errorf("did: %q: %w", d.Method, ErrMethodNotRegistered)
+	}
+
+	id := d.ID
+	if id == "" {
+		id = strings.Join(d.IDStrings, ":")
+	}
+
+	return parseMethodSpecific(syntax, d.Method, id, id, 0)
+}
+
+// parseMethodSpecific decodes id with the syntax of a method, an error is reported as a *ParseError of input,
+// in which the method-specific-id begins at offset
+func parseMethodSpecific(syntax MethodSyntax, method, id, input string, offset int) (interface{}, error) {
+	value, err := syntax.Parse(id)
+	if err != nil {
+		return nil, newParseError(input, offset, ComponentMethodSpecificID, "method-specific-id",
+			fmt.Errorf("%w: %w", ErrInvalidMethodSpecificID, err),
+			"method-specific-id is not valid for method %s: %v", method, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,78 @@
+package did
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRegisterMethod(t *testing.T) {
+	RegisterMethod("test", MethodSyntaxFunc(func(id string) (interface{}, error) {
+		if strings.HasPrefix(id, "x") {
+			return nil, errors.New("must not begin with x")
+		}
+		return strings.ToUpper(id), nil
+	}))
+	defer func() {
+		methodsMu.Lock()
+		delete(methods, "test")
+		methodsMu.Unlock()
+	}()
+
+	assert(t, []string{"key", "test", "web"}, Methods())
+
+	t.Run("panics if a method is registered twice", func(t *testing.T) {
+		defer func() {
+			assert(t, "did: RegisterMethod called twice for method test", recover())
+		}()
+		RegisterMethod("test", MethodSyntaxFunc(nil))
+	})
+
+	t.Run("panics if the method name is not valid", func(t *testing.T) {
+		defer func() {
+			assert(t, "did: RegisterMethod name is not a valid method name Test", recover())
+		}()
+		RegisterMethod("Test", MethodSyntaxFunc(nil))
+	})
+
+	t.Run("validates the method-specific-id when the option is set", func(t *testing.T) {
+		opts := ParseOptions{ValidateMethodSpecificID: true}
+
+		_, err := ParseWithOptions("did:test:abc/path", opts)
+		assert(t, nil, err)
+
+		// methods that are not registered are not validated
+		_, err = ParseWithOptions("did:other:xyz", opts)
+		assert(t, nil, err)
+
+		_, err = Parse("did:test:xyz")
+		assert(t, nil, err)
+
+		_, err = ParseWithOptions("did:test:xyz#frag", opts)
+		var pe *ParseError
+		assert(t, true, errors.As(err, &pe))
+		assert(t, true, errors.Is(err, ErrInvalidMethodSpecificID))
+		assert(t, 9, pe.Offset)
+		assert(t, ComponentMethodSpecificID, pe.Component)
+		assert(t, "did: method-specific-id is not valid for method test: must not begin with x at offset 9 "+
+			"(method-specific-id)", err.Error())
+	})
+
+	t.Run("MethodSpecific returns the decoded value", func(t *testing.T) {
+		d, err := Parse("did:test:abc:def")
+		assert(t, nil, err)
+		value, err := d.MethodSpecific()
+		assert(t, nil, err)
+		assert(t, "ABC:DEF", value)
+
+		value, err = (&DID{Method: "test", IDStrings: []string{"a", "b"}}).MethodSpecific()
+		assert(t, nil, err)
+		assert(t, "A:B", value)
+
+		_, err = (&DID{Method: "test", ID: "xyz"}).MethodSpecific()
+		assert(t, true, errors.Is(err, ErrInvalidMethodSpecificID))
+
+		_, err = (&DID{Method: "other", ID: "xyz"}).MethodSpecific()
+		assert(t, true, errors.Is(err, ErrMethodNotRegistered))
+	})
+}
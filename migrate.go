@@ -0,0 +1,121 @@
+package did
+
+import (
+	"strings"
+	"time"
+)
+
+// legacyParams maps the names of the generic params of the CCG draft of the DID spec to the names of
+// the DID parameters of DID Core that replaced them
+// https://w3c-ccg.github.io/did-spec/#generic-did-parameter-names
+var legacyParams = map[string]string{
+	"service":      ParamService,
+	"version-id":   ParamVersionID,
+	"version-time": ParamVersionTime,
+	"hl":           ParamHashLink,
+}
+
+// A MigrationError is returned by MigrateParams when a DID URL has params that cannot be mapped to DID parameters
+type MigrationError struct {
+	// DID is the DID URL that could not be migrated
+	DID string
+
+	// Params are the params that cannot be mapped
+	Params []Param
+}
+
+// Error returns a description of the error that lists the names of the params that cannot be mapped
+func (e *MigrationError) Error() string {
+	names := make([]string, len(e.Params))
+	for i, param := range e.Params {
+		names[i] = param.Name
+	}
+	return "did: cannot migrate params of " + e.DID + ": " + strings.Join(names, ", ")
+}
+
+// MigrateParams returns a copy of a DID URL parsed with GrammarLegacyCCG, with its `;` separated params
+// moved into its Query as DID Core parameters, ex- did:x:y;service=agent;version-id=3 becomes
+// did:x:y?service=agent&versionId=3. The params are appended to the Query in order:
+//   - service, version-id, version-time and hl are renamed to service, versionId, versionTime and hl,
+//     a version-time must be an RFC 3339 timestamp without fractional seconds and is converted to UTC
+//   - a method specific param, whose name is prefixed with the method of the DID, keeps its name,
+//     ex- did:example:123;example:foo=bar becomes did:example:123?example:foo=bar
+//
+// If a param is not one of these, or if a DID Core parameter would appear more than once in the Query,
+// MigrateParams returns a *MigrationError that lists every param that cannot be mapped.
+// https://www.w3.org/TR/did-core/#did-parameters
+func MigrateParams(d *DID) (*DID, error) {
+	// the DID Core parameters that are already in the Query
+	seen := make(map[string]bool)
+	for _, pair := range strings.Split(d.Query, "&") {
+		name := strings.SplitN(pair, "=", 2)[0]
+		if unescaped, err := unescape(name, ComponentQuery); err == nil {
+			name = unescaped
+		}
+		seen[name] = true
+	}
+
+	var query []string
+	var unmapped []Param
+
+	for _, param := range d.Params {
+		name, value, ok := migrateParam(d.Method, param)
+
+		isCoreParam := name != "" && !strings.Contains(name, ":")
+		if !ok || (isCoreParam && seen[name]) {
+			unmapped = append(unmapped, param)
+			continue
+		}
+		seen[name] = true
+
+		query = append(query, EscapeQueryComponent(name)+"="+EscapeQueryComponent(value))
+	}
+
+	if len(unmapped) > 0 {
+		return nil, &MigrationError{DID: d.String(), Params: unmapped}
+	}
+
+	m := d.clone()
+	m.Params = nil
+	if len(query) > 0 {
+		if m.Query != "" {
+			query = append([]string{m.Query}, query...)
+		}
+		m.Query = strings.Join(query, "&")
+	}
+
+	return m, nil
+}
+
+// migrateParam returns the decoded name and value of the DID Core parameter that replaces a legacy param,
+// ok is false if the param cannot be mapped
+func migrateParam(method string, param Param) (name, value string, ok bool) {
+	name, err := unescape(param.Name, ComponentParam)
+	if err != nil {
+		return "", "", false
+	}
+	value, err = unescape(param.Value, ComponentParam)
+	if err != nil {
+		return "", "", false
+	}
+
+	if coreName, ok := legacyParams[name]; ok {
+		if coreName != ParamVersionTime {
+			return coreName, value, true
+		}
+
+		// versionTime does not have fractional seconds, a timestamp that has them cannot be mapped
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil || t.Nanosecond() != 0 {
+			return "", "", false
+		}
+		return coreName, formatVersionTime(t), true
+	}
+
+	// a method specific param name is prefixed with the method name, ex- example:foo for did:example
+	if i := strings.IndexByte(name, ':'); i > 0 && name[:i] == method && i < len(name)-1 {
+		return name, value, true
+	}
+
+	return "", "", false
+}
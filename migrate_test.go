@@ -0,0 +1,90 @@
+package did
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMigrateParams(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"did:x:y", "did:x:y"},
+		{"did:x:y;service=agent;version-id=3", "did:x:y?service=agent&versionId=3"},
+		{"did:x:y;version-time=2002-10-10T17:00:00Z", "did:x:y?versionTime=2002-10-10T17:00:00Z"},
+		{"did:x:y;version-time=2002-10-10T19:00:00%2B02:00", "did:x:y?versionTime=2002-10-10T17:00:00Z"},
+		{"did:x:y;hl=zQm1", "did:x:y?hl=zQm1"},
+		{"did:x:y;service=a%20b/path?q=1#frag", "did:x:y/path?q=1&service=a%20b#frag"},
+		{"did:x:y;x:foo=bar;x:foo=baz", "did:x:y?x:foo=bar&x:foo=baz"},
+		{"did:x:y;service", "did:x:y?service="},
+	}
+
+	for _, test := range tests {
+		d, err := ParseWithOptions(test.input, ParseOptions{Grammar: GrammarLegacyCCG})
+		assert(t, nil, err, "Input: %s", test.input)
+
+		migrated, err := MigrateParams(d)
+		assert(t, nil, err, "Input: %s", test.input)
+		assert(t, test.expected, migrated.String(), "Input: %s", test.input)
+		assert(t, []Param(nil), migrated.Params, "Input: %s", test.input)
+
+		// the migrated DID URL conforms to DID Core
+		_, err = Parse(migrated.String())
+		assert(t, nil, err, "Input: %s", test.input)
+
+		// and its DID parameters are valid
+		_, err = migrated.Parameters()
+		assert(t, nil, err, "Input: %s", test.input)
+	}
+
+	t.Run("does not change the DID", func(t *testing.T) {
+		d, err := ParseWithOptions("did:x:y;service=agent", ParseOptions{Grammar: GrammarLegacyCCG})
+		assert(t, nil, err)
+		_, err = MigrateParams(d)
+		assert(t, nil, err)
+		assert(t, "did:x:y;service=agent", d.String())
+	})
+
+	t.Run("decodes the params into DID parameters", func(t *testing.T) {
+		d, err := ParseWithOptions("did:x:y;service=a%20b;version-id=3", ParseOptions{Grammar: GrammarLegacyCCG})
+		assert(t, nil, err)
+		migrated, err := MigrateParams(d)
+		assert(t, nil, err)
+		params, err := migrated.Parameters()
+		assert(t, nil, err)
+		assert(t, "a b", params.Service)
+		assert(t, "3", params.VersionID)
+	})
+
+	errorTests := []struct {
+		input    string
+		unmapped []Param
+	}{
+		{"did:x:y;foo=bar", []Param{{Name: "foo", Value: "bar"}}},
+		{"did:x:y;other:foo=bar;service=a", []Param{{Name: "other:foo", Value: "bar"}}},
+		{"did:x:y;x:=bar", []Param{{Name: "x:", Value: "bar"}}},
+		{"did:x:y;version-time=yesterday", []Param{{Name: "version-time", Value: "yesterday"}}},
+		{"did:x:y;version-time=2002-10-10T17:00:00.5Z", []Param{{Name: "version-time", Value: "2002-10-10T17:00:00.5Z"}}},
+		{"did:x:y;service=a;service=b", []Param{{Name: "service", Value: "b"}}},
+		{"did:x:y;service=a?service=b", []Param{{Name: "service", Value: "a"}}},
+		{"did:x:y;version-id=1?version%49d=2", []Param{{Name: "version-id", Value: "1"}}},
+		{"did:x:y;a;b", []Param{{Name: "a"}, {Name: "b"}}},
+	}
+
+	for _, test := range errorTests {
+		d, err := ParseWithOptions(test.input, ParseOptions{Grammar: GrammarLegacyCCG})
+		assert(t, nil, err, "Input: %s", test.input)
+
+		migrated, err := MigrateParams(d)
+		assert(t, true, migrated == nil, "Input: %s", test.input)
+
+		var me *MigrationError
+		assert(t, true, errors.As(err, &me), "Input: %s", test.input)
+		assert(t, test.unmapped, me.Params, "Input: %s", test.input)
+		assert(t, test.input, me.DID, "Input: %s", test.input)
+	}
+
+	_, err := MigrateParams(&DID{Method: "x", ID: "y", Params: []Param{{Name: "a"}, {Name: "x:b"}, {Name: "c"}}})
+	assert(t, "did: cannot migrate params of did:x:y;a;x:b;c: a, c", err.Error())
+}
@@ -0,0 +1,186 @@
+package did
+
+import (
+	"strings"
+)
+
+// Normalize returns a copy of a DID in the normal form described in RFC 3986 section 6.2.2,
+// two DIDs that denote the same identifier have the same normal form:
+//   - the hex digits of pct-encoded octets are uppercase, ex- %2f becomes %2F
+//   - pct-encoded unreserved chars that are allowed in their component are decoded, ex- %2D becomes -
+//   - `.` and `..` segments, once decoded, are removed from an absolute path, ex- /a/%2E%2E/b becomes /b
+//   - an empty path is removed, ex- did:example:123/ becomes did:example:123
+//
+// IDStrings and PathSegments of the normalized DID are always populated from ID and Path.
+// https://tools.ietf.org/html/rfc3986#section-6.2.2
+func (d *DID) Normalize() *DID {
+	n := &DID{Method: d.Method, RelativePath: d.RelativePath}
+
+	idStrings := d.IDStrings
+	if d.ID != "" {
+		idStrings = strings.Split(d.ID, ":")
+	}
+	for _, idString := range idStrings {
+		n.IDStrings = append(n.IDStrings, normalizePercentEncoding(idString, isNotValidIDChar))
+	}
+	n.ID = strings.Join(n.IDStrings, ":")
+
+	for _, param := range d.Params {
+		n.Params = append(n.Params, Param{
+			Name:  normalizePercentEncoding(param.Name, isNotValidParamChar),
+			Value: normalizePercentEncoding(param.Value, isNotValidParamChar),
+		})
+	}
+
+	if d.hasPath() {
+		// pct-encoded octets are decoded before dot segments are removed, so that %2E%2E is removed like ..
+		segments := strings.Split(d.pathString(), "/")
+		for i, segment := range segments {
+			segments[i] = normalizePercentEncoding(segment, isNotValidPathChar)
+		}
+		path := strings.Join(segments, "/")
+		if !d.RelativePath {
+			path = removeDotSegments("/" + path)[1:]
+		}
+
+		// a path of a single empty segment is an empty path
+		if path != "" {
+			n.PathSegments = strings.Split(path, "/")
+			n.Path = path
+		}
+	}
+
+	n.Query = normalizePercentEncoding(d.Query, isNotValidQueryOrFragmentChar)
+	n.Fragment = normalizePercentEncoding(d.Fragment, isNotValidQueryOrFragmentChar)
+
+	return n
+}
+
+// Equal returns true if a and b denote the same DID or DID URL, i.e. their normal forms are equal.
+// Two nil DIDs are equal.
+func Equal(a, b *DID) bool {
+	return Compare(a, b) == 0
+}
+
+// Compare returns an integer comparing the normal forms of two DIDs.
+// The result is 0 if a and b are equal, -1 if a sorts before b, and +1 if a sorts after b.
+// DIDs are ordered by Method, then by IDStrings, Params, PathSegments, Query and Fragment, a nil DID
+// sorts before all other DIDs. A list of components, like IDStrings, is ordered by its elements,
+// a list sorts before any longer list that it is a prefix of.
+func Compare(a, b *DID) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	return compareNormal(a.Normalize(), b.Normalize())
+}
+
+// compareNormal compares two DIDs that are in their normal form
+func compareNormal(a, b *DID) int {
+	if c := strings.Compare(a.Method, b.Method); c != 0 {
+		return c
+	}
+
+	if c := compareStrings(a.IDStrings, b.IDStrings); c != 0 {
+		return c
+	}
+
+	for i := 0; i < len(a.Params) && i < len(b.Params); i++ {
+		if c := strings.Compare(a.Params[i].Name, b.Params[i].Name); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Params[i].Value, b.Params[i].Value); c != 0 {
+			return c
+		}
+	}
+	if c := compareInts(len(a.Params), len(b.Params)); c != 0 {
+		return c
+	}
+
+	if a.RelativePath != b.RelativePath {
+		// absolute paths sort before relative paths
+		if b.RelativePath {
+			return -1
+		}
+		return 1
+	}
+
+	if c := compareStrings(a.PathSegments, b.PathSegments); c != 0 {
+		return c
+	}
+
+	if c := strings.Compare(a.Query, b.Query); c != 0 {
+		return c
+	}
+
+	return strings.Compare(a.Fragment, b.Fragment)
+}
+
+// compareStrings compares two lists of strings element by element,
+// a list that is a prefix of a longer list sorts before it
+func compareStrings(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := strings.Compare(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(a), len(b))
+}
+
+// compareInts compares two ints
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// normalizePercentEncoding uppercases the hex digits of the pct-encoded octets in s, and decodes
+// the octets that are unreserved chars for which isNotValid returns false
+// https://tools.ietf.org/html/rfc3986#section-6.2.2.1
+// https://tools.ietf.org/html/rfc3986#section-6.2.2.2
+func normalizePercentEncoding(s string, isNotValid func(byte) bool) string {
+	if strings.IndexByte(s, '%') < 0 {
+		return s
+	}
+
+	var buf strings.Builder
+	buf.Grow(len(s))
+
+	for i := 0; i < len(s); i++ {
+		char := s[i]
+		if char != '%' || i+2 >= len(s) || isNotHexDigit(s[i+1]) || isNotHexDigit(s[i+2]) {
+			// not a valid pct-encoded octet, leave it as it is
+			buf.WriteByte(char) // nolint, returned error is always nil
+			continue
+		}
+
+		decoded := unhex(s[i+1])<<4 | unhex(s[i+2])
+		if !isNotUnreserved(decoded) && !isNotValid(decoded) {
+			buf.WriteByte(decoded) // nolint, returned error is always nil
+		} else {
+			buf.WriteByte('%')                   // nolint, returned error is always nil
+			buf.WriteByte(upperhex[decoded>>4])  // nolint, returned error is always nil
+			buf.WriteByte(upperhex[decoded&0xF]) // nolint, returned error is always nil
+		}
+		i = i + 2
+	}
+
+	return buf.String()
+}
+
+// isNotUnreserved returns true if a byte is not an unreserved char
+// from the grammar:
+//   unreserved = ALPHA / DIGIT / "-" / "." / "_" / "~"
+func isNotUnreserved(char byte) bool {
+	return isNotAlpha(char) && isNotDigit(char) && char != '-' && char != '.' && char != '_' && char != '~'
+}
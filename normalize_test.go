@@ -0,0 +1,131 @@
+package did
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"did:example:abc":                    "did:example:abc",
+		"did:example:abc%2d":                 "did:example:abc-",
+		"did:example:abc%2D%5f%2e":           "did:example:abc-_.",
+		"did:example:abc%7e":                 "did:example:abc%7E",
+		"did:example:abc%3a":                 "did:example:abc%3A",
+		"did:example:%41:b":                  "did:example:A:b",
+		"did:example:abc/":                   "did:example:abc",
+		"did:example:abc/a/./b/../c":         "did:example:abc/a/c",
+		"did:example:abc/%7euser/%2f":        "did:example:abc/~user/%2F",
+		"did:example:abc?":                   "did:example:abc",
+		"did:example:abc#":                   "did:example:abc",
+		"did:example:abc?a=%2f%26&b=%61":     "did:example:abc?a=%2F%26&b=a",
+		"did:example:abc#key%2d1%23":         "did:example:abc#key-1%23",
+		"did:example:abc/a?b/../c#d/../e%7e": "did:example:abc/a?b/../c#d/../e~",
+		"did:a:b/x/%2E%2E/y":                 "did:a:b/y",
+		"did:a:b/x/%2e%2e/y":                 "did:a:b/y",
+		"did:a:b/x/%2e/y/.%2E":               "did:a:b/x/",
+	}
+
+	for input, expected := range tests {
+		d, err := Parse(input)
+		assert(t, nil, err, "Input: %s", input)
+		assert(t, expected, d.Normalize().String(), "Input: %s", input)
+	}
+
+	t.Run("normalizes legacy params", func(t *testing.T) {
+		d, err := ParseWithOptions("did:example:abc;service=%61gent%3a", ParseOptions{Grammar: GrammarLegacyCCG})
+		assert(t, nil, err)
+		assert(t, "did:example:abc;service=agent%3A", d.Normalize().String())
+	})
+
+	t.Run("populates IDStrings and PathSegments", func(t *testing.T) {
+		d := &DID{Method: "example", ID: "a:b", Path: "c/d"}
+		n := d.Normalize()
+		assert(t, []string{"a", "b"}, n.IDStrings)
+		assert(t, []string{"c", "d"}, n.PathSegments)
+	})
+
+	t.Run("does not remove dot segments from a relative path", func(t *testing.T) {
+		d, err := ParseReference("../a/%7e")
+		assert(t, nil, err)
+		assert(t, "../a/~", d.Normalize().String())
+	})
+
+	t.Run("does not modify the DID", func(t *testing.T) {
+		d, err := Parse("did:example:abc%2d/a/../b")
+		assert(t, nil, err)
+		d.Normalize()
+		assert(t, "did:example:abc%2d/a/../b", d.String())
+	})
+}
+
+func TestEqual(t *testing.T) {
+	equal := [][2]string{
+		{"did:example:abc%2d", "did:example:abc-"},
+		{"did:example:abc%2f", "did:example:abc%2F"},
+		{"did:example:abc?", "did:example:abc"},
+		{"did:example:abc/", "did:example:abc"},
+		{"did:example:abc/a/../b#k", "did:example:abc/b#k"},
+	}
+	for _, pair := range equal {
+		a, err := Parse(pair[0])
+		assert(t, nil, err)
+		b, err := Parse(pair[1])
+		assert(t, nil, err)
+		assert(t, true, Equal(a, b), "Input: %s %s", pair[0], pair[1])
+		assert(t, true, Equal(b, a), "Input: %s %s", pair[1], pair[0])
+	}
+
+	notEqual := [][2]string{
+		{"did:example:abc", "did:example:ABC"},
+		{"did:example:abc", "did:other:abc"},
+		{"did:example:abc%2f", "did:example:abc/"},
+		{"did:example:abc?a", "did:example:abc#a"},
+		{"did:example:a:b", "did:example:a%3Ab"},
+	}
+	for _, pair := range notEqual {
+		a, err := Parse(pair[0])
+		assert(t, nil, err)
+		b, err := Parse(pair[1])
+		assert(t, nil, err)
+		assert(t, false, Equal(a, b), "Input: %s %s", pair[0], pair[1])
+	}
+
+	assert(t, true, Equal(nil, nil))
+	assert(t, false, Equal(nil, &DID{Method: "example", ID: "abc"}))
+}
+
+func TestCompare(t *testing.T) {
+	sorted := []string{
+		"did:a:b",
+		"did:a:b#f",
+		"did:a:b?q",
+		"did:a:b?q#f",
+		"did:a:b/x",
+		"did:a:b/x/y",
+		"did:a:b/y",
+		"did:a:b:c",
+		"did:a:b-c",
+		"did:a:c",
+		"did:b:a",
+	}
+
+	dids := make([]*DID, 0, len(sorted))
+	for i := len(sorted) - 1; i >= 0; i-- {
+		d, err := Parse(sorted[i])
+		assert(t, nil, err)
+		dids = append(dids, d)
+	}
+
+	sort.Slice(dids, func(i, j int) bool { return Compare(dids[i], dids[j]) < 0 })
+
+	for i, d := range dids {
+		assert(t, sorted[i], d.String())
+	}
+
+	a := &DID{Method: "a", ID: "b"}
+	assert(t, 0, Compare(nil, nil))
+	assert(t, -1, Compare(nil, a))
+	assert(t, 1, Compare(a, nil))
+	assert(t, 0, Compare(a, &DID{Method: "a", IDStrings: []string{"b"}}))
+}
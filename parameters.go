@@ -0,0 +1,230 @@
+package did
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The names of the DID parameters that are defined by DID Core
+// https://www.w3.org/TR/did-core/#did-parameters
+const (
+	ParamService      = "service"
+	ParamRelativeRef  = "relativeRef"
+	ParamVersionID    = "versionId"
+	ParamVersionTime  = "versionTime"
+	ParamHashLink     = "hl"
+	ParamTransformKey = "transformKey"
+)
+
+// VersionTimeLayout is the layout of a versionTime value, an XML datetime normalized to UTC
+// without sub-second precision, ex- 2002-10-10T17:00:00Z
+const VersionTimeLayout = "2006-01-02T15:04:05Z"
+
+var (
+	// ErrDuplicateParameter is returned when a DID parameter defined by DID Core appears more than once in a query
+	ErrDuplicateParameter = errors.New("parameter must not appear more than once")
+
+	// ErrInvalidParameter is returned when a DID parameter is malformed
+	ErrInvalidParameter = errors.New("invalid parameter")
+)
+
+// Parameters are the DID parameters in the query of a DID URL.
+// All values are percent decoded.
+// https://www.w3.org/TR/did-core/#did-parameters
+type Parameters struct {
+	// Service identifies a service from the DID document by service ID
+	Service string
+
+	// RelativeRef is a relative URI reference that identifies a resource at a service endpoint
+	RelativeRef string
+
+	// VersionID identifies a specific version of a DID document to be resolved
+	VersionID string
+
+	// VersionTime identifies a certain version timestamp of a DID document to be resolved,
+	// it is the zero time if the parameter is not present
+	VersionTime time.Time
+
+	// HL is a resource hash of the DID document to add integrity protection
+	HL string
+
+	// TransformKey is the expected output form of a DID document
+	TransformKey string
+
+	// Extensions holds all the other parameters, they may be method specific and may appear more than once.
+	// A parameter without a value, ex- foo in ?foo&bar=1, has an empty value, and is encoded without a `=`
+	// if it was parsed without one.
+	Extensions url.Values
+
+	// valueless records the parsed Extensions that have no `=`, by name and by the index of their value,
+	// so that ?foo is not encoded as ?foo=
+	valueless map[string][]bool
+}
+
+// ParseParameters parses the query of a DID URL into its DID parameters.
+// The query is a sequence of `&` separated name=value pairs, the parameters defined by DID Core
+// must not appear more than once and versionTime must be an XML datetime in UTC.
+func ParseParameters(query string) (*Parameters, error) {
+	params := &Parameters{}
+	seen := make(map[string]bool)
+
+	for _, pair := range strings.Split(query, "&") {
+		if pair == "" {
+			continue
+		}
+
+		name, value, hasValue := pair, "", false
+		if i := strings.IndexByte(pair, '='); i >= 0 {
+			name, value, hasValue = pair[:i], pair[i+1:], true
+		}
+
+		name, err := unescape(name, ComponentQuery)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err = unescape(value, ComponentQuery)
+		if err != nil {
+			return nil, err
+		}
+
+		if name == "" {
+			return nil, fmt.Errorf("did: parameter name is empty: %w", ErrInvalidParameter)
+		}
+
+		var field *string
+		switch name {
+		case ParamService:
+			field = &params.Service
+		case ParamRelativeRef:
+			field = &params.RelativeRef
+		case ParamVersionID:
+			field = &params.VersionID
+		case ParamHashLink:
+			field = &params.HL
+		case ParamTransformKey:
+			field = &params.TransformKey
+		case ParamVersionTime:
+			if seen[name] {
+				return nil, fmt.Errorf("did: parameter %q: %w", name, ErrDuplicateParameter)
+			}
+			seen[name] = true
+
+			// versionTime must be in UTC, time.Parse only accepts the literal Z at the end of the layout
+			params.VersionTime, err = time.Parse(VersionTimeLayout, value)
+			if err != nil {
+				return nil, fmt.Errorf("did: parameter %q is not an XML datetime in UTC: %w", name, ErrInvalidParameter)
+			}
+			continue
+		default:
+			if params.Extensions == nil {
+				params.Extensions = url.Values{}
+			}
+			params.Extensions.Add(name, value)
+			if !hasValue {
+				params.setValueless(name, len(params.Extensions[name])-1)
+			}
+			continue
+		}
+
+		if seen[name] {
+			return nil, fmt.Errorf("did: parameter %q: %w", name, ErrDuplicateParameter)
+		}
+		seen[name] = true
+		*field = value
+	}
+
+	return params, nil
+}
+
+// setValueless records that the value at index i of the extension parameter name has no `=`
+func (p *Parameters) setValueless(name string, i int) {
+	if p.valueless == nil {
+		p.valueless = make(map[string][]bool)
+	}
+	flags := p.valueless[name]
+	for len(flags) <= i {
+		flags = append(flags, false)
+	}
+	flags[i] = true
+	p.valueless[name] = flags
+}
+
+// isValueless returns true if the value at index i of the extension parameter name is empty,
+// and was parsed without a `=`
+func (p *Parameters) isValueless(name string, i int) bool {
+	flags := p.valueless[name]
+	return i < len(flags) && flags[i] && p.Extensions[name][i] == ""
+}
+
+// Parameters parses the Query of a DID URL into its DID parameters
+func (d *DID) Parameters() (*Parameters, error) {
+	return ParseParameters(d.Query)
+}
+
+// SetParameters encodes params and replaces the Query of a DID URL with them. The Query is canonicalized
+// by Encode, so d.SetParameters(d.Parameters()) may rewrite a Query that has the same parameters:
+// the DID Core parameters are moved before the Extensions, which are sorted by name, and empty DID Core
+// parameters are dropped, ex- ?x=1&versionId=&service=a becomes ?service=a&x=1
+func (d *DID) SetParameters(params *Parameters) {
+	d.Query = params.Encode()
+}
+
+// Encode encodes the parameters into a canonical query string, which does not depend on the order
+// of the parameters in a parsed query. The parameters defined by DID Core are encoded first, followed by
+// the Extensions sorted by name, names and values are percent encoded as needed. Empty DID Core parameters
+// are omitted, and the Extensions that were parsed without a value are encoded without a `=`.
+func (p *Parameters) Encode() string {
+	var buf strings.Builder
+
+	write := func(name, value string, valueless bool) {
+		if buf.Len() > 0 {
+			buf.WriteByte('&') // nolint, returned error is always nil
+		}
+		buf.WriteString(EscapeQueryComponent(name)) // nolint, returned error is always nil
+		if valueless {
+			return
+		}
+		buf.WriteByte('=')                           // nolint, returned error is always nil
+		buf.WriteString(EscapeQueryComponent(value)) // nolint, returned error is always nil
+	}
+
+	for _, param := range []struct{ name, value string }{
+		{ParamService, p.Service},
+		{ParamRelativeRef, p.RelativeRef},
+		{ParamVersionID, p.VersionID},
+		{ParamVersionTime, formatVersionTime(p.VersionTime)},
+		{ParamHashLink, p.HL},
+		{ParamTransformKey, p.TransformKey},
+	} {
+		if param.value != "" {
+			write(param.name, param.value, false)
+		}
+	}
+
+	names := make([]string, 0, len(p.Extensions))
+	for name := range p.Extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for i, value := range p.Extensions[name] {
+			write(name, value, p.isValueless(name, i))
+		}
+	}
+
+	return buf.String()
+}
+
+// formatVersionTime formats t as a versionTime value, or returns an empty string for the zero time
+func formatVersionTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(VersionTimeLayout)
+}
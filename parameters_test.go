@@ -0,0 +1,155 @@
+package did
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseParameters(t *testing.T) {
+	t.Run("returns empty parameters for an empty query", func(t *testing.T) {
+		p, err := ParseParameters("")
+		assert(t, nil, err)
+		assert(t, &Parameters{}, p)
+	})
+
+	t.Run("extracts the DID Core parameters", func(t *testing.T) {
+		p, err := ParseParameters("service=files&relativeRef=%2Fresume.pdf&versionId=3" +
+			"&versionTime=2002-10-10T17:00:00Z&hl=zQmWvQxTqbG2Z9HPJgG57jjwR154cKhbtJenbyYTWkjgF3e&transformKey=jwk")
+		assert(t, nil, err)
+		assert(t, "files", p.Service)
+		assert(t, "/resume.pdf", p.RelativeRef)
+		assert(t, "3", p.VersionID)
+		assert(t, time.Date(2002, 10, 10, 17, 0, 0, 0, time.UTC), p.VersionTime)
+		assert(t, "zQmWvQxTqbG2Z9HPJgG57jjwR154cKhbtJenbyYTWkjgF3e", p.HL)
+		assert(t, "jwk", p.TransformKey)
+		assert(t, url.Values(nil), p.Extensions)
+	})
+
+	t.Run("collects other parameters in Extensions", func(t *testing.T) {
+		p, err := ParseParameters("foo=1&bar&foo=2&a%20b=c%26d")
+		assert(t, nil, err)
+		assert(t, url.Values{"foo": {"1", "2"}, "bar": {""}, "a b": {"c&d"}}, p.Extensions)
+	})
+
+	t.Run("skips empty pairs", func(t *testing.T) {
+		p, err := ParseParameters("&service=a&&")
+		assert(t, nil, err)
+		assert(t, "a", p.Service)
+	})
+
+	t.Run("returns error if a DID Core parameter repeats", func(t *testing.T) {
+		for _, query := range []string{
+			"service=a&service=b",
+			"versionId=1&versionId=1",
+			"versionTime=2002-10-10T17:00:00Z&versionTime=2002-10-10T17:00:00Z",
+		} {
+			_, err := ParseParameters(query)
+			assert(t, true, errors.Is(err, ErrDuplicateParameter), "Input: %s", query)
+		}
+	})
+
+	t.Run("returns error if versionTime is not an XML datetime in UTC", func(t *testing.T) {
+		for _, query := range []string{
+			"versionTime=2002-10-10T17:00:00",
+			"versionTime=2002-10-10T17:00:00%2B01:00",
+			"versionTime=2002-10-10",
+			"versionTime=",
+		} {
+			_, err := ParseParameters(query)
+			assert(t, true, errors.Is(err, ErrInvalidParameter), "Input: %s", query)
+		}
+	})
+
+	t.Run("returns error if a parameter name is empty", func(t *testing.T) {
+		_, err := ParseParameters("=a")
+		assert(t, true, errors.Is(err, ErrInvalidParameter))
+	})
+
+	t.Run("returns error if percent encoding is invalid", func(t *testing.T) {
+		_, err := ParseParameters("service=a%2")
+		assert(t, true, errors.Is(err, ErrInvalidPercentEncoding))
+	})
+}
+
+func TestParametersEncode(t *testing.T) {
+	t.Run("encodes DID Core parameters in order followed by sorted extensions", func(t *testing.T) {
+		p := &Parameters{
+			Service:     "files",
+			RelativeRef: "/a b&c",
+			VersionTime: time.Date(2002, 10, 10, 18, 0, 0, 5, time.FixedZone("", 3600)),
+			Extensions:  url.Values{"z": {"1"}, "a": {"x=y", ""}},
+		}
+		assert(t, "service=files&relativeRef=/a%20b%26c&versionTime=2002-10-10T17:00:00Z&a=x%3Dy&a=&z=1", p.Encode())
+	})
+
+	t.Run("round trips through a DID URL", func(t *testing.T) {
+		p := &Parameters{
+			Service:      "agent",
+			VersionID:    "4",
+			HL:           "zQm",
+			TransformKey: "jwk",
+			Extensions:   url.Values{"example:foo": {"100%", "+"}},
+		}
+
+		d := &DID{Method: "example", ID: "123"}
+		d.SetParameters(p)
+
+		parsed, err := Parse(d.String())
+		assert(t, nil, err)
+
+		decoded, err := parsed.Parameters()
+		assert(t, nil, err)
+		assert(t, p, decoded)
+	})
+
+	t.Run("round trips parameters without a value", func(t *testing.T) {
+		for _, query := range []string{"foo", "foo=", "foo&foo=", "foo=&foo", "a&b=1&c", "service=files&x", "foo=1&foo&foo=2"} {
+			p, err := ParseParameters(query)
+			assert(t, nil, err, "Query: %s", query)
+			assert(t, query, p.Encode(), "Query: %s", query)
+
+			d, err := Parse("did:example:123?" + query)
+			assert(t, nil, err, "Query: %s", query)
+			params, err := d.Parameters()
+			assert(t, nil, err, "Query: %s", query)
+			d.SetParameters(params)
+			assert(t, "did:example:123?"+query, d.String(), "Query: %s", query)
+		}
+	})
+
+	t.Run("canonicalizes the query of a DID URL", func(t *testing.T) {
+		tests := map[string]string{
+			"versionId=&service=x":                      "service=x",
+			"x=1&versionId=&service=a":                  "service=a&x=1",
+			"z=1&a=2&hl=h&service=s":                    "service=s&hl=h&a=2&z=1",
+			"service=s&hl=h&a=2&z=1":                    "service=s&hl=h&a=2&z=1",
+			"b=2&a=1&b=1":                               "a=1&b=2&b=1",
+			"versionTime=2002-10-10T17:00:00Z&service=": "versionTime=2002-10-10T17:00:00Z",
+		}
+
+		for query, expected := range tests {
+			d, err := Parse("did:example:123?" + query)
+			assert(t, nil, err, "Query: %s", query)
+			params, err := d.Parameters()
+			assert(t, nil, err, "Query: %s", query)
+			d.SetParameters(params)
+			assert(t, expected, d.Query, "Query: %s", query)
+
+			// a canonical query is not changed
+			params, err = d.Parameters()
+			assert(t, nil, err, "Query: %s", query)
+			d.SetParameters(params)
+			assert(t, expected, d.Query, "Query: %s", query)
+		}
+	})
+
+	t.Run("encodes a value that replaces an empty value", func(t *testing.T) {
+		p, err := ParseParameters("foo&bar")
+		assert(t, nil, err)
+		p.Extensions["foo"][0] = "1"
+		p.Extensions.Add("bar", "")
+		assert(t, "bar&bar=&foo=1", p.Encode())
+	})
+}
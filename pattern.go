@@ -0,0 +1,303 @@
+package did
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidPattern is returned by Compile when a pattern is not valid
+var ErrInvalidPattern = errors.New("invalid pattern")
+
+// A Pattern matches DIDs and DID URLs by their components, it is compiled from a DID URL in which
+// the method, the idstrings, the path segments, the query and the fragment may contain wildcards:
+//   - `*` matches any sequence of characters within a component, ex- did:web:*.example.com
+//   - `{name}` matches a non-empty sequence of characters within a component and binds it to name,
+//     ex- did:example:org:{tenant}, a name may appear once in each component, and must match the same
+//     value in every component in which it appears
+//   - a last idstring or path segment that is only `*` matches one or more idstrings or path segments,
+//     ex- did:example:org:* matches did:example:org:a and did:example:org:a:b
+//
+// A component that is not in the pattern must be empty, ex- did:key:* does not match did:key:z6Mk#key-1,
+// but did:key:*#* does. DIDs are compared in their normal form, see Normalize, and a DID with legacy
+// params never matches.
+type Pattern struct {
+	pattern string
+
+	method    glob
+	idStrings []glob
+	path      []glob
+	query     *glob
+	fragment  *glob
+}
+
+// glob is the compiled pattern of one component, it is a sequence of literals, wildcards and bindings
+type glob []globPart
+
+// globPart is a literal, if name and wildcard are not set
+type globPart struct {
+	literal  string
+	wildcard bool   // matches any sequence of characters
+	name     string // matches a non-empty sequence of characters and binds it to name
+}
+
+// Compile parses a pattern, it returns an error that wraps ErrInvalidPattern if the pattern is not valid
+func Compile(pattern string) (*Pattern, error) {
+	if !strings.HasPrefix(pattern, "did:") {
+		return nil, fmt.Errorf("did: pattern %q does not begin with 'did:' prefix: %w", pattern, ErrInvalidPattern)
+	}
+	p := &Pattern{pattern: pattern}
+	rest := pattern[len("did:"):]
+	var err error
+
+	if i := strings.IndexByte(rest, '#'); i >= 0 {
+		p.fragment, err = compileOptional(rest[i+1:], ComponentFragment, isNotValidQueryOrFragmentChar)
+		if err != nil {
+			return nil, err
+		}
+		rest = rest[:i]
+	}
+
+	if i := strings.IndexByte(rest, '?'); i >= 0 {
+		p.query, err = compileOptional(rest[i+1:], ComponentQuery, isNotValidQueryOrFragmentChar)
+		if err != nil {
+			return nil, err
+		}
+		rest = rest[:i]
+	}
+
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		// the normal form of a DID does not have an empty path, a pattern with an empty path matches no path
+		if path := rest[i+1:]; path != "" {
+			p.path, err = compileList(strings.Split(path, "/"), ComponentPath, isNotValidPathChar)
+			if err != nil {
+				return nil, err
+			}
+		}
+		rest = rest[:i]
+	}
+
+	elements := strings.Split(rest, ":")
+	if len(elements) < 2 {
+		return nil, fmt.Errorf("did: pattern %q does not have a method-specific-id: %w", pattern, ErrInvalidPattern)
+	}
+
+	p.method, err = compileGlob(elements[0], ComponentMethod, func(char byte) bool {
+		return isNotDigit(char) && isNotSmallLetter(char)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(p.method) == 0 {
+		return nil, fmt.Errorf("did: pattern %q has an empty method: %w", pattern, ErrInvalidPattern)
+	}
+
+	p.idStrings, err = compileList(elements[1:], ComponentMethodSpecificID, isNotValidIDChar)
+	if err != nil {
+		return nil, err
+	}
+	if last := p.idStrings[len(p.idStrings)-1]; len(last) == 0 {
+		return nil, fmt.Errorf("did: pattern %q has an empty last idstring: %w", pattern, ErrInvalidPattern)
+	}
+
+	return p, nil
+}
+
+// MustCompile is like Compile but panics if the pattern is not valid
+func MustCompile(pattern string) *Pattern {
+	p, err := Compile(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the source of the pattern
+func (p *Pattern) String() string {
+	return p.pattern
+}
+
+// Match returns true if d matches the pattern, and the values bound to the names of the pattern
+func (p *Pattern) Match(d *DID) (map[string]string, bool) {
+	if len(d.Params) > 0 {
+		return nil, false
+	}
+	n := d.Normalize()
+	bindings := make(map[string]string)
+
+	if !p.method.match(n.Method, bindings) ||
+		!matchList(p.idStrings, n.IDStrings, bindings) ||
+		!matchList(p.path, n.PathSegments, bindings) ||
+		!matchOptional(p.query, n.Query, bindings) ||
+		!matchOptional(p.fragment, n.Fragment, bindings) {
+		return nil, false
+	}
+
+	return bindings, true
+}
+
+// compileList compiles the patterns of the elements of a list component, like the idstrings
+func compileList(elements []string, component Component, isNotValid func(byte) bool) ([]glob, error) {
+	globs := make([]glob, len(elements))
+	for i, element := range elements {
+		var err error
+		globs[i], err = compileGlob(element, component, isNotValid)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return globs, nil
+}
+
+// compileOptional compiles the pattern of a query or fragment
+func compileOptional(s string, component Component, isNotValid func(byte) bool) (*glob, error) {
+	g, err := compileGlob(s, component, isNotValid)
+	if err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// compileGlob compiles the pattern of a component, its literals are checked with isNotValid and normalized
+func compileGlob(s string, component Component, isNotValid func(byte) bool) (glob, error) {
+	var g glob
+
+	for s != "" {
+		switch i := strings.IndexAny(s, "*{}"); {
+		case i < 0:
+			i = len(s)
+			fallthrough
+		case i > 0:
+			literal := s[:i]
+			if err := checkChars(literal, component, component.String(), isNotValid); err != nil {
+				return nil, fmt.Errorf("did: pattern %s %q is not valid: %v: %w", component, literal, err, ErrInvalidPattern)
+			}
+			g = append(g, globPart{literal: normalizePercentEncoding(literal, isNotValid)})
+			s = s[i:]
+		case s[0] == '*':
+			g = append(g, globPart{wildcard: true})
+			s = s[1:]
+		case s[0] == '{':
+			end := strings.IndexByte(s, '}')
+			if end < 0 {
+				return nil, fmt.Errorf("did: pattern %s %q has a `{` without a `}`: %w", component, s, ErrInvalidPattern)
+			}
+			name := s[1:end]
+			if name == "" || strings.IndexFunc(name, func(r rune) bool {
+				return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_')
+			}) >= 0 {
+				return nil, fmt.Errorf("did: pattern %s has a name that is not made of letters, digits and _ - %q: %w",
+					component, name, ErrInvalidPattern)
+			}
+			for _, part := range g {
+				if part.name == name {
+					return nil, fmt.Errorf("did: pattern %s binds %q more than once: %w", component, name, ErrInvalidPattern)
+				}
+			}
+			g = append(g, globPart{name: name})
+			s = s[end+1:]
+		default:
+			return nil, fmt.Errorf("did: pattern %s %q has a `}` without a `{`: %w", component, s, ErrInvalidPattern)
+		}
+	}
+
+	return g, nil
+}
+
+// isRest returns true if a glob is a lone `*`, which matches the rest of a list when it is the last element
+func (g glob) isRest() bool {
+	return len(g) == 1 && g[0].wildcard
+}
+
+// matchList matches the elements of a list component, if globs is empty the list must be empty
+func matchList(globs []glob, elements []string, bindings map[string]string) bool {
+	if len(globs) > 0 && globs[len(globs)-1].isRest() && len(elements) >= len(globs) {
+		// the trailing `*` matches one or more elements
+		elements = elements[:len(globs)-1]
+		globs = globs[:len(globs)-1]
+	}
+
+	if len(globs) != len(elements) {
+		return false
+	}
+	for i, g := range globs {
+		if !g.match(elements[i], bindings) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchOptional matches a query or a fragment, if g is nil s must be empty
+func matchOptional(g *glob, s string, bindings map[string]string) bool {
+	if g == nil {
+		return s == ""
+	}
+	return g.match(s, bindings)
+}
+
+// match returns true if s matches the glob, the values of its names are added to bindings.
+// A name that is already bound must match the same value again. When there is more than one way to match,
+// the wildcards and names that come first match the shortest values, ex- {a}-{b} binds a to x in x-y-z.
+// A name is bound once within a glob, see compileGlob, so the glob is matched in O(len(g) * len(s)) time
+// rather than by backtracking, which would take exponential time on inputs like did:example:*a*a*a*b.
+func (g glob) match(s string, bindings map[string]string) bool {
+	// ok[i][j] is true if g[i:] matches s[j:]
+	ok := make([][]bool, len(g)+1)
+	ok[len(g)] = make([]bool, len(s)+1)
+	ok[len(g)][len(s)] = true
+
+	for i := len(g) - 1; i >= 0; i-- {
+		ok[i] = make([]bool, len(s)+1)
+		part := g[i]
+		literal, isLiteral := part.literal, part.name == "" && !part.wildcard
+		if value, bound := bindings[part.name]; part.name != "" && bound {
+			literal, isLiteral = value, true
+		}
+
+		// rest is true if g[i+1:] matches s[k:] for some k >= j
+		rest := false
+		for j := len(s); j >= 0; j-- {
+			switch {
+			case isLiteral:
+				ok[i][j] = strings.HasPrefix(s[j:], literal) && ok[i+1][j+len(literal)]
+			case part.wildcard:
+				rest = rest || ok[i+1][j]
+				ok[i][j] = rest
+			default:
+				// a name matches at least one character
+				ok[i][j] = rest
+				rest = rest || ok[i+1][j]
+			}
+		}
+	}
+
+	if !ok[0][0] {
+		return false
+	}
+
+	// bind the names along the match in which the wildcards and names that come first are the shortest
+	j := 0
+	for i, part := range g {
+		switch value, bound := bindings[part.name]; {
+		case part.name == "" && !part.wildcard:
+			j += len(part.literal)
+		case part.name != "" && bound:
+			j += len(value)
+		default:
+			k := j
+			if part.name != "" {
+				k++
+			}
+			for !ok[i+1][k] {
+				k++
+			}
+			if part.name != "" {
+				bindings[part.name] = s[j:k]
+			}
+			j = k
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,117 @@
+package did
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		input    string
+		bindings map[string]string
+	}{
+		{"did:web:*.example.com", "did:web:api.example.com", map[string]string{}},
+		{"did:web:*.example.com", "did:web:a.b.example.com", map[string]string{}},
+		{"did:web:*.example.com", "did:web:example.com", nil},
+		{"did:web:*.example.com", "did:web:api.example.com:user", nil},
+		{"did:web:*.example.com", "did:web:api.example.com/path", nil},
+		{"did:key:*", "did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK", map[string]string{}},
+		{"did:key:*", "did:key:z6Mk#z6Mk", nil},
+		{"did:key:*#*", "did:key:z6Mk#z6Mk", map[string]string{}},
+		{"did:key:*#*", "did:key:z6Mk", map[string]string{}},
+		{"did:key:*", "did:web:z6Mk", nil},
+		{"did:*:123", "did:example:123", map[string]string{}},
+		{"did:example:org:{tenant}:*#key-*", "did:example:org:acme:user:1#key-2", map[string]string{"tenant": "acme"}},
+		{"did:example:org:{tenant}:*#key-*", "did:example:org:acme#key-2", nil},
+		{"did:example:org:{tenant}:*#key-*", "did:example:org:acme:user#keys-2", nil},
+		{"did:example:{a}-{b}", "did:example:x-y-z", map[string]string{"a": "x", "b": "y-z"}},
+		{"did:example:{a}:{a}", "did:example:x:x", map[string]string{"a": "x"}},
+		{"did:example:{a}:{a}", "did:example:x:y", nil},
+		{"did:example:{a}", "did:example::x", nil},
+		{"did:example:{a}.*.{b}", "did:example:x.y.z.w", map[string]string{"a": "x", "b": "z.w"}},
+		{"did:example:*{a}", "did:example:xyz", map[string]string{"a": "xyz"}},
+		{"did:example:{a}:*{a}", "did:example:x:yzx", map[string]string{"a": "x"}},
+		{"did:example:{a}:*{a}", "did:example:x:yz", nil},
+		{"did:example:*a*a*a*a*a*b", "did:example:" + strings.Repeat("a", 60), nil},
+		{"did:example:*a*a*a*a*a*b", "did:example:" + strings.Repeat("a", 60) + "b", map[string]string{}},
+		{"did:example::*", "did:example::x", map[string]string{}},
+		{"did:example:1/docs/*", "did:example:1/docs/a/b", map[string]string{}},
+		{"did:example:1/docs/*", "did:example:1/docs", nil},
+		{"did:example:1/{kind}/x", "did:example:1/docs/x", map[string]string{"kind": "docs"}},
+		{"did:example:1/", "did:example:1/", map[string]string{}},
+		{"did:example:1?service=*", "did:example:1?service=files", map[string]string{}},
+		{"did:example:1?service=*", "did:example:1", nil},
+		{"did:example:1", "did:example:1?service=files", nil},
+		{"did:example:a%2d", "did:example:a-", map[string]string{}},
+		{"did:example:a-", "did:example:a%2D", map[string]string{}},
+		{"did:example:*", "did:example:1;service=agent", nil},
+	}
+
+	for _, test := range tests {
+		p, err := Compile(test.pattern)
+		assert(t, nil, err, "Pattern: %s", test.pattern)
+		assert(t, test.pattern, p.String())
+
+		d, err := ParseWithOptions(test.input, ParseOptions{Grammar: GrammarLegacyCCG})
+		if err != nil {
+			d, err = Parse(test.input)
+		}
+		assert(t, nil, err, "Input: %s", test.input)
+
+		bindings, ok := p.Match(d)
+		assert(t, test.bindings != nil, ok, "Pattern: %s Input: %s", test.pattern, test.input)
+		assert(t, test.bindings, bindings, "Pattern: %s Input: %s", test.pattern, test.input)
+	}
+}
+
+func TestCompile(t *testing.T) {
+	invalid := []string{
+		"",
+		"web:*",
+		"did:web",
+		"did:web:",
+		"did::123",
+		"did:Web:123",
+		"did:web:a b",
+		"did:web:{",
+		"did:web:}",
+		"did:web:{}",
+		"did:web:{a-b}",
+		"did:web:{a}{a}",
+		"did:web:{a}.*.{a}",
+		"did:web:1/a b",
+		"did:web:1?%zz",
+		"did:web:1#a#b",
+	}
+
+	for _, pattern := range invalid {
+		p, err := Compile(pattern)
+		assert(t, true, p == nil, "Pattern: %s", pattern)
+		assert(t, true, errors.Is(err, ErrInvalidPattern), "Pattern: %s", pattern)
+	}
+
+	t.Run("MustCompile panics if the pattern is not valid", func(t *testing.T) {
+		defer func() {
+			assert(t, true, recover() != nil)
+		}()
+		MustCompile("did:web")
+	})
+}
+
+func TestPatternMatchTime(t *testing.T) {
+	// backtracking over the wildcards takes exponential time on these inputs
+	p := MustCompile("did:example:*a*a*a*a*a*a*a*a*a*a*b#{x}*{y}*z")
+	input := "did:example:" + strings.Repeat("a", 4096) + "#" + strings.Repeat("b", 4096)
+	d, err := Parse(input)
+	assert(t, nil, err)
+
+	start := time.Now()
+	_, ok := p.Match(d)
+	elapsed := time.Since(start)
+
+	assert(t, false, ok)
+	assert(t, true, elapsed < 2*time.Second, "Match took %v", elapsed)
+}
@@ -0,0 +1,215 @@
+package did
+
+import (
+	"strings"
+)
+
+// ParseReference parses a DID URL or a relative DID URL reference into a DID structure.
+// A relative reference has no Method or ID, it is made up of a path, a query and a fragment,
+// ex- `#key-1`, `?service=files&relativeRef=/a`, `/path` or `path`.
+// from the grammar:
+//   relative-ref  = relative-part [ "?" query ] [ "#" fragment ]
+//   relative-part = path-absolute / path-noscheme / path-empty
+// https://tools.ietf.org/html/rfc3986#section-4.2
+// https://www.w3.org/TR/did-core/#relative-did-urls
+func ParseReference(input string) (*DID, error) {
+	if strings.HasPrefix(input, "did:") {
+		return Parse(input)
+	}
+
+	d := &DID{}
+
+	err := parseInto(d, input, ParseOptions{}, nil, parseRelativeRef)
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// parseRelativeRef is a parserStep that decides which part of a relative reference the input begins with
+func parseRelativeRef(p *parser) parserStep {
+	input := p.input
+
+	if input == "" {
+		// an empty reference refers to the base DID
+		return nil
+	}
+
+	switch input[0] {
+	case '/':
+		if len(input) > 1 && input[1] == '/' {
+			// from the grammar:
+			//   path-absolute = "/" [ segment-nz *( "/" segment ) ]
+			// `//` begins a network-path reference, which would have an authority
+			return p.errorf(1, ComponentPath, "path-absolute", ErrInvalidReference,
+				"reference must not begin with `//`")
+		}
+		p.currentIndex = 0
+		return parsePath
+	case '?':
+		p.currentIndex = 0
+		return parseQuery
+	case '#':
+		p.currentIndex = 0
+		return parseFragment
+	}
+
+	// from the grammar:
+	//   path-noscheme = segment-nz-nc *( "/" segment )
+	//   segment-nz-nc = 1*( unreserved / pct-encoded / sub-delims / "@" )
+	// a `:` in the first segment would make the reference look like a URI with a scheme
+	for currentIndex := 0; currentIndex < len(input); currentIndex++ {
+		char := input[currentIndex]
+		if char == '/' || char == '?' || char == '#' {
+			break
+		}
+		if char == ':' {
+			return p.errorf(currentIndex, ComponentPath, "path-noscheme", ErrInvalidReference,
+				"first segment of a relative path must not contain `:`")
+		}
+	}
+
+	p.currentIndex = -1
+	p.out.RelativePath = true
+	return parsePath
+}
+
+// referenceString encodes a relative DID URL reference
+func (d *DID) referenceString() string {
+	var buf strings.Builder
+
+	if d.hasPath() {
+		path := d.pathString()
+		if !d.RelativePath {
+			buf.WriteByte('/') // nolint, returned error is always nil
+		} else if strings.Contains(strings.SplitN(path, "/", 2)[0], ":") {
+			// a `:` in the first segment of a relative path must be preceded by `./`
+			// https://tools.ietf.org/html/rfc3986#section-4.2
+			buf.WriteString("./") // nolint, returned error is always nil
+		}
+		buf.WriteString(path) // nolint, returned error is always nil
+	}
+
+	if d.Query != "" {
+		buf.WriteByte('?')       // nolint, returned error is always nil
+		buf.WriteString(d.Query) // nolint, returned error is always nil
+	}
+
+	if d.Fragment != "" {
+		buf.WriteByte('#')          // nolint, returned error is always nil
+		buf.WriteString(d.Fragment) // nolint, returned error is always nil
+	}
+
+	return buf.String()
+}
+
+// ResolveReference resolves a DID URL reference against d, which is the base DID, and returns
+// the resulting DID URL. It implements the algorithm in RFC 3986 section 5.2, with the method and
+// method-specific-id of the base DID taking the place of the authority of a URI, so a relative
+// path is resolved against the path of the base DID and cannot remove its method-specific-id.
+// If ref is not relative, a copy of ref with dot segments removed from its path is returned.
+// https://tools.ietf.org/html/rfc3986#section-5.2
+func (d *DID) ResolveReference(ref *DID) *DID {
+	var target DID
+
+	// the slices of the target are copies, so appending to them cannot modify d or ref
+	if ref.Method != "" {
+		// ref is a DID URL, only its path needs to be normalized
+		target = *ref
+		target.IDStrings = append([]string(nil), ref.IDStrings...)
+		target.Params = append([]Param(nil), ref.Params...)
+		target.PathSegments = append([]string(nil), ref.PathSegments...)
+		if ref.hasPath() {
+			target.setPath(removeDotSegments("/" + ref.pathString()))
+		}
+		return &target
+	}
+
+	target.Method = d.Method
+	target.ID = d.ID
+	target.IDStrings = append([]string(nil), d.IDStrings...)
+	target.Params = append([]Param(nil), d.Params...)
+
+	switch {
+	case !ref.hasPath():
+		if d.hasPath() {
+			target.setPath("/" + d.pathString())
+		}
+		target.Query = d.Query
+		if ref.Query != "" {
+			target.Query = ref.Query
+		}
+	case !ref.RelativePath:
+		target.setPath(removeDotSegments("/" + ref.pathString()))
+		target.Query = ref.Query
+	default:
+		target.setPath(removeDotSegments(mergePaths(d, ref)))
+		target.Query = ref.Query
+	}
+
+	target.Fragment = ref.Fragment
+
+	return &target
+}
+
+// hasPath returns true if a DID has a Path or PathSegments
+func (d *DID) hasPath() bool {
+	return d.Path != "" || len(d.PathSegments) > 0
+}
+
+// pathString returns the Path of a DID, or its PathSegments joined with `/` if Path is empty
+func (d *DID) pathString() string {
+	if d.Path != "" {
+		return d.Path
+	}
+	return strings.Join(d.PathSegments, "/")
+}
+
+// setPath sets the Path and PathSegments of a DID from an absolute path
+func (d *DID) setPath(path string) {
+	d.Path = strings.TrimPrefix(path, "/")
+	d.PathSegments = strings.Split(d.Path, "/")
+}
+
+// mergePaths merges the relative path of ref with the path of the base DID
+// https://tools.ietf.org/html/rfc3986#section-5.2.3
+func mergePaths(base, ref *DID) string {
+	if !base.hasPath() {
+		return "/" + ref.pathString()
+	}
+
+	basePath := "/" + base.pathString()
+	return basePath[:strings.LastIndexByte(basePath, '/')+1] + ref.pathString()
+}
+
+// removeDotSegments removes the `.` and `..` segments from an absolute path
+// https://tools.ietf.org/html/rfc3986#section-5.2.4
+func removeDotSegments(input string) string {
+	var output []string
+
+	segments := strings.Split(input, "/")[1:]
+	for i, segment := range segments {
+		last := i == len(segments)-1
+
+		switch segment {
+		case ".":
+			if last {
+				// a trailing `.` leaves a trailing `/`
+				output = append(output, "")
+			}
+		case "..":
+			if len(output) > 0 {
+				output = output[:len(output)-1]
+			}
+			if last {
+				// a trailing `..` leaves a trailing `/`
+				output = append(output, "")
+			}
+		default:
+			output = append(output, segment)
+		}
+	}
+
+	return "/" + strings.Join(output, "/")
+}
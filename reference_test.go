@@ -0,0 +1,192 @@
+package did
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	t.Run("parses a DID URL", func(t *testing.T) {
+		d, err := ParseReference("did:example:123/a?b#c")
+		assert(t, nil, err)
+		assert(t, "example", d.Method)
+		assert(t, "a", d.Path)
+		assert(t, "b", d.Query)
+		assert(t, "c", d.Fragment)
+	})
+
+	t.Run("parses a fragment reference", func(t *testing.T) {
+		d, err := ParseReference("#key-1")
+		assert(t, nil, err)
+		assert(t, "", d.Method)
+		assert(t, "key-1", d.Fragment)
+		assert(t, "#key-1", d.String())
+	})
+
+	t.Run("parses a query reference", func(t *testing.T) {
+		d, err := ParseReference("?service=files&relativeRef=/a")
+		assert(t, nil, err)
+		assert(t, "service=files&relativeRef=/a", d.Query)
+		assert(t, "?service=files&relativeRef=/a", d.String())
+	})
+
+	t.Run("parses an absolute path reference", func(t *testing.T) {
+		d, err := ParseReference("/a/b?c#d")
+		assert(t, nil, err)
+		assert(t, false, d.RelativePath)
+		assert(t, "a/b", d.Path)
+		assert(t, []string{"a", "b"}, d.PathSegments)
+		assert(t, "/a/b?c#d", d.String())
+	})
+
+	t.Run("parses a relative path reference", func(t *testing.T) {
+		d, err := ParseReference("../a/b#d")
+		assert(t, nil, err)
+		assert(t, true, d.RelativePath)
+		assert(t, "../a/b", d.Path)
+		assert(t, []string{"..", "a", "b"}, d.PathSegments)
+		assert(t, "../a/b#d", d.String())
+	})
+
+	t.Run("parses an empty reference", func(t *testing.T) {
+		d, err := ParseReference("")
+		assert(t, nil, err)
+		assert(t, &DID{}, d)
+	})
+
+	t.Run("returns error for invalid references", func(t *testing.T) {
+		refs := []string{
+			"//host/path",
+			"g:h",
+			"did:a",
+			"a^b",
+			"?a b",
+			"#%zz",
+		}
+		for _, ref := range refs {
+			_, err := ParseReference(ref)
+			assert(t, false, err == nil, "Input: %s", ref)
+		}
+	})
+
+	t.Run("returns ErrInvalidReference for a network-path reference", func(t *testing.T) {
+		_, err := ParseReference("//host/path")
+		assert(t, true, errors.Is(err, ErrInvalidReference))
+
+		_, err = ParseReference("a/g:h")
+		assert(t, nil, err)
+
+		_, err = ParseReference("g:h/a")
+		assert(t, true, errors.Is(err, ErrInvalidReference))
+	})
+
+	t.Run("prefixes a relative path with a colon in its first segment with ./", func(t *testing.T) {
+		d := &DID{Path: "g:h", RelativePath: true}
+		assert(t, "./g:h", d.String())
+	})
+}
+
+func TestResolveReference(t *testing.T) {
+	// the examples from RFC 3986 section 5.4
+	// https://tools.ietf.org/html/rfc3986#section-5.4
+	base, err := Parse("did:example:123/b/c/d;p?q")
+	assert(t, nil, err)
+
+	tests := map[string]string{
+		"did:other:456#k": "did:other:456#k",
+		"g":               "did:example:123/b/c/g",
+		"./g":             "did:example:123/b/c/g",
+		"g/":              "did:example:123/b/c/g/",
+		"/g":              "did:example:123/g",
+		"?y":              "did:example:123/b/c/d;p?y",
+		"g?y":             "did:example:123/b/c/g?y",
+		"#s":              "did:example:123/b/c/d;p?q#s",
+		"g#s":             "did:example:123/b/c/g#s",
+		"g?y#s":           "did:example:123/b/c/g?y#s",
+		";x":              "did:example:123/b/c/;x",
+		"g;x":             "did:example:123/b/c/g;x",
+		"g;x?y#s":         "did:example:123/b/c/g;x?y#s",
+		"":                "did:example:123/b/c/d;p?q",
+		".":               "did:example:123/b/c/",
+		"./":              "did:example:123/b/c/",
+		"..":              "did:example:123/b/",
+		"../":             "did:example:123/b/",
+		"../g":            "did:example:123/b/g",
+		"../..":           "did:example:123/",
+		"../../":          "did:example:123/",
+		"../../g":         "did:example:123/g",
+		"../../../g":      "did:example:123/g",
+		"../../../../g":   "did:example:123/g",
+		"/./g":            "did:example:123/g",
+		"/../g":           "did:example:123/g",
+		"g.":              "did:example:123/b/c/g.",
+		".g":              "did:example:123/b/c/.g",
+		"g..":             "did:example:123/b/c/g..",
+		"..g":             "did:example:123/b/c/..g",
+		"./../g":          "did:example:123/b/g",
+		"./g/.":           "did:example:123/b/c/g/",
+		"g/./h":           "did:example:123/b/c/g/h",
+		"g/../h":          "did:example:123/b/c/h",
+		"g;x=1/./y":       "did:example:123/b/c/g;x=1/y",
+		"g;x=1/../y":      "did:example:123/b/c/y",
+		"g?y/./x":         "did:example:123/b/c/g?y/./x",
+		"g?y/../x":        "did:example:123/b/c/g?y/../x",
+		"g#s/./x":         "did:example:123/b/c/g#s/./x",
+		"g#s/../x":        "did:example:123/b/c/g#s/../x",
+	}
+
+	for input, expected := range tests {
+		ref, err := ParseReference(input)
+		assert(t, nil, err, "Input: %s", input)
+		assert(t, expected, base.ResolveReference(ref).String(), "Input: %s", input)
+	}
+}
+
+func TestResolveReferenceWithoutPath(t *testing.T) {
+	base, err := Parse("did:example:123?versionId=1")
+	assert(t, nil, err)
+
+	tests := map[string]string{
+		"#key-1":              "did:example:123?versionId=1#key-1",
+		"?service=files":      "did:example:123?service=files",
+		"g":                   "did:example:123/g",
+		"../g":                "did:example:123/g",
+		"/a/b":                "did:example:123/a/b",
+		"did:example:123/a/.": "did:example:123/a/",
+	}
+
+	for input, expected := range tests {
+		ref, err := ParseReference(input)
+		assert(t, nil, err, "Input: %s", input)
+		resolved := base.ResolveReference(ref)
+		assert(t, expected, resolved.String(), "Input: %s", input)
+		assert(t, false, resolved.RelativePath, "Input: %s", input)
+	}
+}
+
+func TestResolveReferenceCopies(t *testing.T) {
+	base, err := ParseWithOptions("did:example:a:b;service=agent/x/y", ParseOptions{Grammar: GrammarLegacyCCG})
+	assert(t, nil, err)
+	ref, err := ParseReference("#key-1")
+	assert(t, nil, err)
+
+	// the slices of the target are not shared with the base DID
+	resolved := base.ResolveReference(ref)
+	resolved.IDStrings[0] = "z"
+	resolved.Params[0].Value = "z"
+	resolved.PathSegments[0] = "z"
+	assert(t, "did:example:a:b;service=agent/x/y", base.String())
+	assert(t, []string{"a", "b"}, base.IDStrings)
+	assert(t, "agent", base.Params[0].Value)
+	assert(t, []string{"x", "y"}, base.PathSegments)
+
+	// nor with a reference that is not relative
+	ref = base
+	resolved = (&DID{Method: "other", ID: "1"}).ResolveReference(ref)
+	resolved.IDStrings[0] = "z"
+	resolved.Params[0].Value = "z"
+	resolved.PathSegments[0] = "z"
+	assert(t, []string{"a", "b"}, ref.IDStrings)
+	assert(t, "agent", ref.Params[0].Value)
+	assert(t, []string{"x", "y"}, ref.PathSegments)
+}
@@ -0,0 +1,519 @@
+package did
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrDuplicateKey is returned when a key appears more than once in a map of a strictly decoded DID document
+	ErrDuplicateKey = errors.New("duplicate key")
+
+	// ErrInvalidType is returned when a value of a DID document does not have the type required by DID Core
+	ErrInvalidType = errors.New("value has the wrong type")
+
+	// ErrReservedProperty is returned when the Properties of a DID document, a verification method or a service
+	// hold a property that is defined by DID Core, which must be set in its field instead
+	ErrReservedProperty = errors.New("property is defined by DID Core")
+)
+
+// A DocumentError describes why a DID document could not be encoded or decoded, and where in the document
+// the problem was found
+type DocumentError struct {
+	// Pointer is the JSON pointer of the value that caused the error, ex- /verificationMethod/0/id
+	// https://tools.ietf.org/html/rfc6901
+	Pointer string
+
+	// Err is one of the sentinel errors declared in this package, for example ErrInvalidType,
+	// or the *ParseError of a DID URL that is not valid
+	Err error
+
+	// a human readable description of the problem
+	message string
+}
+
+// Error returns a description of the error that includes its JSON pointer
+func (e *DocumentError) Error() string {
+	return fmt.Sprintf("did: %s at %q", e.message, e.Pointer)
+}
+
+// Unwrap returns the error wrapped by a DocumentError
+func (e *DocumentError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeOptions are the options to decode a DID document
+type DecodeOptions struct {
+	// Strict rejects maps in which a key appears more than once, and null values of the properties defined
+	// by DID Core. Otherwise the last value of a duplicate key is used, and a null property is left unset.
+	Strict bool
+}
+
+// documentError returns a *DocumentError at the given pointer
+func documentError(pointer string, err error, format string, args ...interface{}) *DocumentError {
+	return &DocumentError{Pointer: pointer, Err: err, message: fmt.Sprintf(format, args...)}
+}
+
+// appendPointer returns the JSON pointer of a key or an index in the value at pointer
+// https://tools.ietf.org/html/rfc6901#section-3
+func appendPointer(pointer string, key interface{}) string {
+	s := fmt.Sprint(key)
+	s = strings.ReplaceAll(s, "~", "~0")
+	s = strings.ReplaceAll(s, "/", "~1")
+	return pointer + "/" + s
+}
+
+// The representations of a DID document are produced from, and consumed into, a tree of generic values,
+// which are nil, bool, float64, string, []interface{} and map[string]interface{}, like the values that
+// encoding/json decodes into an interface{}. Values in Properties may be of any type that the
+// representation can encode.
+
+// toValue returns the generic value of a DID document
+func (doc *Document) toValue() (map[string]interface{}, error) {
+	m, err := propertiesValue(doc.Properties, "", documentProperties)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(doc.Context) == 1 {
+		m["@context"] = doc.Context[0]
+	} else if len(doc.Context) > 1 {
+		m["@context"] = doc.Context
+	}
+
+	if doc.ID != nil {
+		if m["id"], err = didValue(doc.ID, "/id"); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(doc.Controller) > 0 {
+		controllers := make([]interface{}, len(doc.Controller))
+		for i, controller := range doc.Controller {
+			if controllers[i], err = didValue(controller, appendPointer("/controller", i)); err != nil {
+				return nil, err
+			}
+		}
+		m["controller"] = singleOrSet(controllers)
+	}
+
+	if len(doc.AlsoKnownAs) > 0 {
+		alsoKnownAs := make([]interface{}, len(doc.AlsoKnownAs))
+		for i, uri := range doc.AlsoKnownAs {
+			alsoKnownAs[i] = uri
+		}
+		m["alsoKnownAs"] = alsoKnownAs
+	}
+
+	if len(doc.VerificationMethod) > 0 {
+		methods := make([]interface{}, len(doc.VerificationMethod))
+		for i := range doc.VerificationMethod {
+			methods[i], err = doc.VerificationMethod[i].toValue(appendPointer("/verificationMethod", i))
+			if err != nil {
+				return nil, err
+			}
+		}
+		m["verificationMethod"] = methods
+	}
+
+	for _, name := range Relationships {
+		entries := *doc.relationship(name)
+		if len(entries) == 0 {
+			continue
+		}
+
+		values := make([]interface{}, len(entries))
+		for i, entry := range entries {
+			pointer := appendPointer("/"+name, i)
+			switch {
+			case entry.Reference != nil:
+				values[i], err = didValue(entry.Reference, pointer)
+			case entry.Method != nil:
+				values[i], err = entry.Method.toValue(pointer)
+			default:
+				err = documentError(pointer, ErrInvalidType, "entry has neither a Reference nor a Method")
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		m[name] = values
+	}
+
+	if len(doc.Service) > 0 {
+		services := make([]interface{}, len(doc.Service))
+		for i := range doc.Service {
+			services[i], err = doc.Service[i].toValue(appendPointer("/service", i))
+			if err != nil {
+				return nil, err
+			}
+		}
+		m["service"] = services
+	}
+
+	return m, nil
+}
+
+// toValue returns the generic value of a verification method at pointer
+func (method *VerificationMethod) toValue(pointer string) (map[string]interface{}, error) {
+	m, err := propertiesValue(method.Properties, pointer, methodProperties)
+	if err != nil {
+		return nil, err
+	}
+
+	if method.ID != nil {
+		if m["id"], err = didValue(method.ID, pointer+"/id"); err != nil {
+			return nil, err
+		}
+	}
+	if method.Type != "" {
+		m["type"] = method.Type
+	}
+	if method.Controller != nil {
+		if m["controller"], err = didValue(method.Controller, pointer+"/controller"); err != nil {
+			return nil, err
+		}
+	}
+	if method.PublicKeyJwk != nil {
+		m["publicKeyJwk"] = method.PublicKeyJwk
+	}
+	if method.PublicKeyMultibase != "" {
+		m["publicKeyMultibase"] = method.PublicKeyMultibase
+	}
+
+	return m, nil
+}
+
+// toValue returns the generic value of a service at pointer
+func (service *Service) toValue(pointer string) (map[string]interface{}, error) {
+	m, err := propertiesValue(service.Properties, pointer, serviceProperties)
+	if err != nil {
+		return nil, err
+	}
+
+	if service.ID != "" {
+		m["id"] = service.ID
+	}
+	if len(service.Type) > 0 {
+		types := make([]interface{}, len(service.Type))
+		for i, t := range service.Type {
+			types[i] = t
+		}
+		m["type"] = singleOrSet(types)
+	}
+	if len(service.ServiceEndpoint) > 0 {
+		m["serviceEndpoint"] = singleOrSet(service.ServiceEndpoint)
+	}
+
+	return m, nil
+}
+
+// The properties that are defined by DID Core, which are not in Properties
+var (
+	documentProperties = []string{"@context", "id", "controller", "alsoKnownAs", "verificationMethod",
+		RelationshipAuthentication, RelationshipAssertionMethod, RelationshipKeyAgreement,
+		RelationshipCapabilityInvocation, RelationshipCapabilityDelegation, "service"}
+	methodProperties  = []string{"id", "type", "controller", "publicKeyJwk", "publicKeyMultibase"}
+	serviceProperties = []string{"id", "type", "serviceEndpoint"}
+)
+
+// propertiesValue returns a copy of properties, it returns an error if one of them is reserved
+func propertiesValue(properties map[string]interface{}, pointer string, reserved []string) (map[string]interface{}, error) {
+	m := make(map[string]interface{}, len(properties)+len(reserved))
+	for name, value := range properties {
+		if isReserved(name, reserved) {
+			return nil, documentError(appendPointer(pointer, name), ErrReservedProperty,
+				"property %q must not be in Properties", name)
+		}
+		m[name] = value
+	}
+	return m, nil
+}
+
+// didValue returns the string of a DID URL or a relative DID URL reference at pointer
+func didValue(d *DID, pointer string) (string, error) {
+	s := d.String()
+	if s == "" {
+		return "", documentError(pointer, errCannotEncode, "DID URL cannot be encoded")
+	}
+	return s, nil
+}
+
+// singleOrSet returns the only value of a set, or the set if it does not have exactly one value
+func singleOrSet(values []interface{}) interface{} {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return values
+}
+
+// decoder converts the generic value of a DID document into a Document
+type decoder struct {
+	opts DecodeOptions
+}
+
+// document returns the DID document of a generic value
+func (dc *decoder) document(v interface{}) (*Document, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, documentError("", ErrInvalidType, "DID document must be a map, not %s", typeName(v))
+	}
+
+	doc := &Document{}
+	var err error
+
+	for _, name := range sortedKeys(m) {
+		value := m[name]
+		pointer := appendPointer("", name)
+
+		if value == nil && !dc.opts.Strict && isReserved(name, documentProperties) {
+			// a null property is left unset
+			continue
+		}
+
+		switch name {
+		case "@context":
+			doc.Context, err = setOf(dc, value, pointer, true, func(v interface{}, pointer string) (interface{}, error) {
+				switch v.(type) {
+				case string, map[string]interface{}:
+					return v, nil
+				}
+				return nil, documentError(pointer, ErrInvalidType, "context must be a string or a map, not %s",
+					typeName(v))
+			})
+		case "id":
+			doc.ID, err = dc.did(value, pointer, Parse)
+		case "controller":
+			doc.Controller, err = setOf(dc, value, pointer, true, func(v interface{}, pointer string) (*DID, error) {
+				return dc.did(v, pointer, Parse)
+			})
+		case "alsoKnownAs":
+			doc.AlsoKnownAs, err = setOf(dc, value, pointer, false, dc.string)
+		case "verificationMethod":
+			doc.VerificationMethod, err = setOf(dc, value, pointer, false,
+				func(v interface{}, pointer string) (VerificationMethod, error) {
+					method, err := dc.method(v, pointer)
+					if err != nil {
+						return VerificationMethod{}, err
+					}
+					return *method, nil
+				})
+		case RelationshipAuthentication, RelationshipAssertionMethod, RelationshipKeyAgreement,
+			RelationshipCapabilityInvocation, RelationshipCapabilityDelegation:
+			*doc.relationship(name), err = setOf(dc, value, pointer, false, dc.relationshipEntry)
+		case "service":
+			doc.Service, err = setOf(dc, value, pointer, false, dc.service)
+		default:
+			if doc.Properties == nil {
+				doc.Properties = make(map[string]interface{})
+			}
+			doc.Properties[name] = value
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return doc, nil
+}
+
+// method returns the verification method of a generic value at pointer
+func (dc *decoder) method(v interface{}, pointer string) (*VerificationMethod, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, documentError(pointer, ErrInvalidType, "verification method must be a map, not %s", typeName(v))
+	}
+
+	method := &VerificationMethod{}
+	var err error
+
+	for _, name := range sortedKeys(m) {
+		value := m[name]
+		pointer := appendPointer(pointer, name)
+
+		if value == nil && !dc.opts.Strict && isReserved(name, methodProperties) {
+			continue
+		}
+
+		switch name {
+		case "id":
+			method.ID, err = dc.did(value, pointer, ParseReference)
+		case "type":
+			method.Type, err = dc.string(value, pointer)
+		case "controller":
+			method.Controller, err = dc.did(value, pointer, Parse)
+		case "publicKeyJwk":
+			var ok bool
+			if method.PublicKeyJwk, ok = value.(map[string]interface{}); !ok {
+				err = documentError(pointer, ErrInvalidType, "publicKeyJwk must be a map, not %s", typeName(value))
+			}
+		case "publicKeyMultibase":
+			method.PublicKeyMultibase, err = dc.string(value, pointer)
+		default:
+			if method.Properties == nil {
+				method.Properties = make(map[string]interface{})
+			}
+			method.Properties[name] = value
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return method, nil
+}
+
+// relationshipEntry returns the entry of a verification relationship of a generic value at pointer,
+// which is a DID URL string or an embedded verification method
+func (dc *decoder) relationshipEntry(v interface{}, pointer string) (RelationshipEntry, error) {
+	if _, ok := v.(string); ok {
+		reference, err := dc.did(v, pointer, ParseReference)
+		return RelationshipEntry{Reference: reference}, err
+	}
+
+	if _, ok := v.(map[string]interface{}); !ok {
+		return RelationshipEntry{}, documentError(pointer, ErrInvalidType,
+			"verification relationship entry must be a string or a map, not %s", typeName(v))
+	}
+
+	method, err := dc.method(v, pointer)
+	return RelationshipEntry{Method: method}, err
+}
+
+// service returns the service of a generic value at pointer
+func (dc *decoder) service(v interface{}, pointer string) (Service, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return Service{}, documentError(pointer, ErrInvalidType, "service must be a map, not %s", typeName(v))
+	}
+
+	service := Service{}
+	var err error
+
+	for _, name := range sortedKeys(m) {
+		value := m[name]
+		pointer := appendPointer(pointer, name)
+
+		if value == nil && !dc.opts.Strict && isReserved(name, serviceProperties) {
+			continue
+		}
+
+		switch name {
+		case "id":
+			service.ID, err = dc.string(value, pointer)
+		case "type":
+			service.Type, err = setOf(dc, value, pointer, true, dc.string)
+		case "serviceEndpoint":
+			service.ServiceEndpoint, err = setOf(dc, value, pointer, true, func(v interface{}, pointer string) (interface{}, error) {
+				switch v.(type) {
+				case string, map[string]interface{}:
+					return v, nil
+				}
+				return nil, documentError(pointer, ErrInvalidType, "service endpoint must be a string or a map, not %s",
+					typeName(v))
+			})
+		default:
+			if service.Properties == nil {
+				service.Properties = make(map[string]interface{})
+			}
+			service.Properties[name] = value
+		}
+
+		if err != nil {
+			return Service{}, err
+		}
+	}
+
+	return service, nil
+}
+
+// setOf returns the elements of a set of generic values at pointer, if single is true v may also be a single element
+func setOf[T any](dc *decoder, v interface{}, pointer string, single bool,
+	element func(interface{}, string) (T, error)) ([]T, error) {
+	values, ok := v.([]interface{})
+	if !ok {
+		if !single {
+			return nil, documentError(pointer, ErrInvalidType, "value must be a set, not %s", typeName(v))
+		}
+		e, err := element(v, pointer)
+		if err != nil {
+			return nil, err
+		}
+		return []T{e}, nil
+	}
+
+	elements := make([]T, len(values))
+	for i, value := range values {
+		var err error
+		if elements[i], err = element(value, appendPointer(pointer, i)); err != nil {
+			return nil, err
+		}
+	}
+	return elements, nil
+}
+
+// string returns a generic value at pointer that must be a string
+func (dc *decoder) string(v interface{}, pointer string) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", documentError(pointer, ErrInvalidType, "value must be a string, not %s", typeName(v))
+	}
+	return s, nil
+}
+
+// did returns the DID URL of a generic value at pointer, which must be a string that parse accepts
+func (dc *decoder) did(v interface{}, pointer string, parse func(string) (*DID, error)) (*DID, error) {
+	s, err := dc.string(v, pointer)
+	if err != nil {
+		return nil, err
+	}
+	d, err := parse(s)
+	if err != nil {
+		return nil, documentError(pointer, err, "%q is not a valid DID URL: %v", s, err)
+	}
+	return d, nil
+}
+
+// isReserved returns true if name is one of the reserved property names
+func isReserved(name string, reserved []string) bool {
+	for _, r := range reserved {
+		if name == r {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the keys of a map in order, so a document with several errors always reports the same one
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// typeName returns the name of the type of a generic value, for error messages
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case float64:
+		return "a number"
+	case string:
+		return "a string"
+	case []interface{}:
+		return "a set"
+	case map[string]interface{}:
+		return "a map"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
@@ -0,0 +1,67 @@
+package did
+
+// A Span is the range of bytes [Start, End) of a component in the input of the parser.
+// The zero Span means that the component is not present in the input, an empty component
+// has a Span with Start equal to End, ex- the query of did:example:123? is Span{16, 16}
+type Span struct {
+	Start int
+	End   int
+}
+
+// ParamSpan holds the spans of the name and value of a Param
+type ParamSpan struct {
+	Name  Span
+	Value Span
+}
+
+// Spans holds the spans of all the components of a DID URL in the input of the parser,
+// they can be used to highlight the syntax of a DID URL, to point at an error,
+// or to rewrite a component in place without scanning the input again.
+type Spans struct {
+	// Scheme is the span of `did`, not including the `:` that follows it
+	Scheme Span
+
+	// Method is the span of the method name
+	Method Span
+
+	// ID is the span of the method-specific-id
+	ID Span
+
+	// IDStrings holds the span of each `:` separated idstring in the method-specific-id
+	IDStrings []Span
+
+	// Params holds the spans of each param, params are only parsed by GrammarLegacyCCG
+	Params []ParamSpan
+
+	// Path is the span of the path, not including its leading `/`
+	Path Span
+
+	// PathSegments holds the span of each `/` separated path segment
+	PathSegments []Span
+
+	// Query is the span of the query, not including the `?` that precedes it
+	Query Span
+
+	// Fragment is the span of the fragment, not including the `#` that precedes it
+	Fragment Span
+}
+
+// Text returns the part of input that a Span covers
+func (s Span) Text(input string) string {
+	return input[s.Start:s.End]
+}
+
+// ParseWithSpans parses the input string into a DID structure using the given options, and reports
+// the span of each of its components in the input. If the input is not valid, the spans of the
+// components that were parsed before the error are returned with the error.
+func ParseWithSpans(input string, opts ParseOptions) (*DID, *Spans, error) {
+	d := &DID{}
+	spans := &Spans{}
+
+	err := parseInto(d, input, opts, spans, checkLength)
+	if err != nil {
+		return nil, spans, err
+	}
+
+	return d, spans, nil
+}
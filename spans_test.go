@@ -0,0 +1,71 @@
+package did
+
+import (
+	"testing"
+)
+
+func TestParseWithSpans(t *testing.T) {
+	t.Run("reports the span of each component", func(t *testing.T) {
+		input := "did:example:123:456/a//b?c=d#e"
+		d, spans, err := ParseWithSpans(input, ParseOptions{})
+		assert(t, nil, err)
+		assert(t, "did:example:123:456/a//b?c=d#e", d.String())
+
+		assert(t, Span{0, 3}, spans.Scheme)
+		assert(t, Span{4, 11}, spans.Method)
+		assert(t, Span{12, 19}, spans.ID)
+		assert(t, []Span{{12, 15}, {16, 19}}, spans.IDStrings)
+		assert(t, []ParamSpan(nil), spans.Params)
+		assert(t, Span{20, 24}, spans.Path)
+		assert(t, []Span{{20, 21}, {22, 22}, {23, 24}}, spans.PathSegments)
+		assert(t, Span{25, 28}, spans.Query)
+		assert(t, Span{29, 30}, spans.Fragment)
+
+		assert(t, "did", spans.Scheme.Text(input))
+		assert(t, d.Method, spans.Method.Text(input))
+		assert(t, d.ID, spans.ID.Text(input))
+		assert(t, d.Path, spans.Path.Text(input))
+		assert(t, d.Query, spans.Query.Text(input))
+		assert(t, d.Fragment, spans.Fragment.Text(input))
+		for i, span := range spans.IDStrings {
+			assert(t, d.IDStrings[i], span.Text(input))
+		}
+		for i, span := range spans.PathSegments {
+			assert(t, d.PathSegments[i], span.Text(input))
+		}
+	})
+
+	t.Run("reports zero spans for missing components and empty spans for empty ones", func(t *testing.T) {
+		_, spans, err := ParseWithSpans("did:example:123?", ParseOptions{})
+		assert(t, nil, err)
+		assert(t, Span{}, spans.Path)
+		assert(t, []Span(nil), spans.PathSegments)
+		assert(t, Span{16, 16}, spans.Query)
+		assert(t, Span{}, spans.Fragment)
+	})
+
+	t.Run("reports the span of params", func(t *testing.T) {
+		input := "did:example:123;service=agent;foo;bar="
+		d, spans, err := ParseWithSpans(input, ParseOptions{Grammar: GrammarLegacyCCG})
+		assert(t, nil, err)
+		assert(t, []ParamSpan{
+			{Name: Span{16, 23}, Value: Span{24, 29}},
+			{Name: Span{30, 33}},
+			{Name: Span{34, 37}, Value: Span{38, 38}},
+		}, spans.Params)
+
+		for i, span := range spans.Params {
+			assert(t, d.Params[i].Name, span.Name.Text(input))
+			assert(t, d.Params[i].Value, span.Value.Text(input))
+		}
+	})
+
+	t.Run("returns the spans parsed before an error", func(t *testing.T) {
+		d, spans, err := ParseWithSpans("did:example:123/a/b^", ParseOptions{})
+		assert(t, false, err == nil)
+		assert(t, true, d == nil)
+		assert(t, Span{4, 11}, spans.Method)
+		assert(t, []Span{{12, 15}}, spans.IDStrings)
+		assert(t, []Span{{16, 17}}, spans.PathSegments)
+	})
+}
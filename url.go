@@ -0,0 +1,54 @@
+package did
+
+import (
+	"net/url"
+)
+
+// URL returns a DID URL as a *url.URL, which is the same as the result of url.Parse(d.String()).
+// The method, method-specific-id and path of a DID are opaque to a URL, so they are in Opaque in their
+// escaped form, RawQuery is the Query and Fragment is the unescaped Fragment, with RawFragment holding
+// its escaped form. A relative DID URL reference has a Path and RawPath instead of an Opaque.
+// If d cannot be encoded, i.e. its String is empty, the URL only has its query and fragment.
+// https://tools.ietf.org/html/rfc3986#section-3
+func (d *DID) URL() *url.URL {
+	u := &url.URL{RawQuery: d.Query}
+
+	if d.Method != "" || d.ID != "" || len(d.IDStrings) > 0 || len(d.Params) > 0 {
+		// Opaque is the DID URL up to its query, without the did: scheme
+		opaque := (&DID{Method: d.Method, ID: d.ID, IDStrings: d.IDStrings, Params: d.Params,
+			Path: d.Path, PathSegments: d.PathSegments}).String()
+		if opaque != "" {
+			u.Scheme = "did"
+			u.Opaque = opaque[len("did:"):]
+		}
+	} else if d.hasPath() {
+		path := (&DID{Path: d.Path, PathSegments: d.PathSegments, RelativePath: d.RelativePath}).referenceString()
+		u.Path, u.RawPath = unescapeURL(path)
+	}
+
+	if d.Fragment != "" {
+		u.Fragment, u.RawFragment = unescapeURL(d.Fragment)
+	}
+
+	return u
+}
+
+// FromURL returns the DID URL, or the relative DID URL reference, that u represents.
+// It is the same as Parse(u.String()) for a URL with a scheme, and ParseReference(u.String())
+// for a URL without one, so the escaped forms of the path and fragment are preserved.
+func FromURL(u *url.URL) (*DID, error) {
+	if u.Scheme == "" {
+		return ParseReference(u.String())
+	}
+	return Parse(u.String())
+}
+
+// unescapeURL returns the unescaped and the escaped form of a part of a DID URL, for the fields of a url.URL.
+// If s is not validly escaped, it is returned as the unescaped form, with an empty escaped form.
+func unescapeURL(s string) (string, string) {
+	unescaped, err := url.PathUnescape(s)
+	if err != nil {
+		return s, ""
+	}
+	return unescaped, s
+}
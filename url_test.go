@@ -0,0 +1,93 @@
+package did
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestURL(t *testing.T) {
+	inputs := []string{
+		"did:example:123",
+		"did:example:123:456/a%2Fb/c",
+		"did:example:123/",
+		"did:example:123?service=files&relativeRef=%2Fresume.pdf",
+		"did:example:123#keys-1",
+		"did:example:123#frag%20x",
+		"did:example:123#a/b?c",
+		"did:example:123/a/b?c=d#e",
+		"did:web:example.com%3A3000:user",
+	}
+
+	for _, input := range inputs {
+		d, err := Parse(input)
+		assert(t, nil, err, "Input: %s", input)
+
+		u := d.URL()
+		assert(t, input, u.String(), "Input: %s", input)
+
+		expected, err := url.Parse(input)
+		assert(t, nil, err, "Input: %s", input)
+		assert(t, expected.String(), u.String(), "Input: %s", input)
+		assert(t, expected.Fragment, u.Fragment, "Input: %s", input)
+		assert(t, expected.Query(), u.Query(), "Input: %s", input)
+
+		fromURL, err := FromURL(u)
+		assert(t, nil, err, "Input: %s", input)
+		assert(t, d, fromURL, "Input: %s", input)
+
+		fromURL, err = FromURL(expected)
+		assert(t, nil, err, "Input: %s", input)
+		assert(t, d, fromURL, "Input: %s", input)
+	}
+
+	t.Run("maps the parts of a DID URL to the fields of a URL", func(t *testing.T) {
+		d, err := Parse("did:example:123/a%2Fb?c=%20#d%20e")
+		assert(t, nil, err)
+
+		u := d.URL()
+		assert(t, "did", u.Scheme)
+		assert(t, "example:123/a%2Fb", u.Opaque)
+		assert(t, "", u.Path)
+		assert(t, "c=%20", u.RawQuery)
+		assert(t, " ", u.Query().Get("c"))
+		assert(t, "d e", u.Fragment)
+		assert(t, "d%20e", u.EscapedFragment())
+	})
+
+	t.Run("maps a relative DID URL reference to a relative URL", func(t *testing.T) {
+		for _, input := range []string{"#keys-1", "/a%2Fb?c", "a/b#c", "./a:b", "?service=files"} {
+			ref, err := ParseReference(input)
+			assert(t, nil, err, "Input: %s", input)
+
+			u := ref.URL()
+			assert(t, "", u.Scheme, "Input: %s", input)
+			assert(t, input, u.String(), "Input: %s", input)
+
+			fromURL, err := FromURL(u)
+			assert(t, nil, err, "Input: %s", input)
+			assert(t, ref.String(), fromURL.String(), "Input: %s", input)
+		}
+
+		u := (&DID{Path: "a/b", RelativePath: true}).URL()
+		assert(t, "a/b", u.Path)
+	})
+
+	t.Run("returns a ParseError if the URL is not a DID URL", func(t *testing.T) {
+		for _, input := range []string{"https://example.com/a", "did://host/a", "did:/a", "//host/a"} {
+			u, err := url.Parse(input)
+			assert(t, nil, err, "Input: %s", input)
+
+			d, err := FromURL(u)
+			assert(t, true, d == nil, "Input: %s", input)
+
+			var pe *ParseError
+			assert(t, true, errors.As(err, &pe), "Input: %s", input)
+		}
+	})
+
+	t.Run("a DID that cannot be encoded only has its query and fragment", func(t *testing.T) {
+		u := (&DID{ID: "123", Query: "a", Fragment: "b"}).URL()
+		assert(t, "?a#b", u.String())
+	})
+}
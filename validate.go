@@ -0,0 +1,250 @@
+package did
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// A ValidationCode identifies a rule of DID Core that a DID document breaks. The codes are stable,
+// so they can be used to filter or suppress the problems that Validate finds, like the codes of a linter.
+type ValidationCode string
+
+// The codes of the problems that Validate finds
+const (
+	// ValidationMissingID is used when the document does not have an id
+	ValidationMissingID ValidationCode = "missing-id"
+
+	// ValidationIDIsURL is used when the id of the document is a DID URL rather than a DID
+	// https://www.w3.org/TR/did-core/#did-subject
+	ValidationIDIsURL ValidationCode = "id-is-url"
+
+	// ValidationControllerNotDID is used when a controller of the document or of a verification method
+	// is a DID URL or a relative DID URL reference rather than a DID
+	// https://www.w3.org/TR/did-core/#did-controller
+	ValidationControllerNotDID ValidationCode = "controller-not-did"
+
+	// ValidationMethodMissingID is used when a verification method does not have an id
+	ValidationMethodMissingID ValidationCode = "method-missing-id"
+
+	// ValidationMethodIDNotURL is used when the id of a verification method is a DID rather than a DID URL
+	// https://www.w3.org/TR/did-core/#verification-methods
+	ValidationMethodIDNotURL ValidationCode = "method-id-not-url"
+
+	// ValidationMethodIDUnresolvable is used when the id of a verification method is a relative DID URL
+	// reference, and the document does not have a DID to resolve it against
+	ValidationMethodIDUnresolvable ValidationCode = "method-id-unresolvable"
+
+	// ValidationDuplicateMethodID is used when two verification methods have the same id
+	ValidationDuplicateMethodID ValidationCode = "duplicate-method-id"
+
+	// ValidationMissingKey is used when a verification method has no verification material, which is
+	// a publicKeyJwk or a publicKeyMultibase, or in Properties one of the registered publicKeyBase58,
+	// publicKeyHex, publicKeyPem, blockchainAccountId or ethereumAddress
+	ValidationMissingKey ValidationCode = "missing-key"
+
+	// ValidationMultipleKeys is used when a verification method has more than one verification material,
+	// ex- both a publicKeyJwk and a publicKeyBase58
+	// https://www.w3.org/TR/did-core/#verification-material
+	ValidationMultipleKeys ValidationCode = "multiple-keys"
+
+	// ValidationMethodNotFound is used when a verification relationship refers to a verification method
+	// that is not in the document
+	ValidationMethodNotFound ValidationCode = "method-not-found"
+
+	// ValidationServiceMissingID is used when a service does not have an id
+	ValidationServiceMissingID ValidationCode = "service-missing-id"
+
+	// ValidationServiceIDNotURI is used when the id of a service is neither a URI nor a relative DID URL reference
+	// https://www.w3.org/TR/did-core/#services
+	ValidationServiceIDNotURI ValidationCode = "service-id-not-uri"
+
+	// ValidationDuplicateServiceID is used when two services have the same id
+	ValidationDuplicateServiceID ValidationCode = "duplicate-service-id"
+)
+
+// A ValidationError is a problem that Validate found in a DID document
+type ValidationError struct {
+	// Code identifies the rule that the document breaks
+	Code ValidationCode
+
+	// Pointer is the JSON pointer of the value that breaks the rule in the JSON representation
+	// of the document, ex- /verificationMethod/0/id
+	// https://tools.ietf.org/html/rfc6901
+	Pointer string
+
+	// Message is a human readable description of the problem
+	Message string
+}
+
+// Error returns a description of the problem that includes its code and its JSON pointer
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("did: %s: %s at %q", e.Code, e.Message, e.Pointer)
+}
+
+// Validate checks the rules of DID Core that the types of a Document cannot enforce, and returns the problems
+// that it finds in the order of the JSON representation of the document, or nil if there are none:
+// the id of the document and the controllers must be DIDs, the ids of the verification methods must be
+// unique DID URLs that can be resolved against the id of the document, each method must have exactly one
+// verification material, the methods that the verification relationships refer to must be in the document, and the ids
+// of the services must be unique URIs. Relative DID URL references are resolved before they are compared.
+func (doc *Document) Validate() []ValidationError {
+	v := &validator{doc: doc}
+
+	switch {
+	case doc.ID == nil:
+		v.add(ValidationMissingID, "", "DID document does not have an id")
+	case doc.ID.IsURL() || doc.ID.Method == "":
+		v.add(ValidationIDIsURL, "/id", "id %s is not a DID", doc.ID)
+	}
+
+	for i, controller := range doc.Controller {
+		v.controller(controller, setPointer("/controller", i, len(doc.Controller)))
+	}
+
+	for i := range doc.VerificationMethod {
+		v.method(&doc.VerificationMethod[i], appendPointer("/verificationMethod", i))
+	}
+
+	for _, name := range Relationships {
+		for i, entry := range *doc.relationship(name) {
+			pointer := appendPointer("/"+name, i)
+			switch {
+			case entry.Reference != nil:
+				if doc.VerificationMethodByID(entry.Reference) == nil {
+					v.add(ValidationMethodNotFound, pointer, "%s refers to %s, which is not in the document",
+						name, entry.Reference)
+				}
+			case entry.Method != nil:
+				v.method(entry.Method, pointer)
+			}
+		}
+	}
+
+	for i := range doc.Service {
+		v.service(&doc.Service[i], appendPointer("/service", i))
+	}
+
+	return v.errors
+}
+
+// validator collects the problems of a DID document, and the ids that it has seen to find duplicates
+type validator struct {
+	doc    *Document
+	errors []ValidationError
+
+	methods  Set
+	services Set
+
+	// the ids of the services that are URIs but not DID URLs
+	serviceURIs map[string]bool
+}
+
+// add adds a problem at pointer
+func (v *validator) add(code ValidationCode, pointer string, format string, args ...interface{}) {
+	v.errors = append(v.errors, ValidationError{Code: code, Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+// controller checks that a controller at pointer is a DID
+func (v *validator) controller(controller *DID, pointer string) {
+	if controller != nil && (controller.IsURL() || controller.Method == "") {
+		v.add(ValidationControllerNotDID, pointer, "controller %s is not a DID", controller)
+	}
+}
+
+// method checks a verification method at pointer
+func (v *validator) method(method *VerificationMethod, pointer string) {
+	idPointer := appendPointer(pointer, "id")
+
+	switch {
+	case method.ID == nil:
+		v.add(ValidationMethodMissingID, pointer, "verification method does not have an id")
+	case method.ID.Method == "" && (v.doc.ID == nil || v.doc.ID.Method == ""):
+		v.add(ValidationMethodIDUnresolvable, idPointer,
+			"id %s is relative, and the DID document does not have a DID to resolve it against", method.ID)
+	default:
+		id := v.doc.ResolveReference(method.ID)
+		if !id.IsURL() {
+			v.add(ValidationMethodIDNotURL, idPointer, "id %s of a verification method is not a DID URL", method.ID)
+		} else if !v.methods.Add(id) {
+			v.add(ValidationDuplicateMethodID, idPointer, "another verification method has the id %s", id)
+		}
+	}
+
+	if method.Controller != nil {
+		v.controller(method.Controller, appendPointer(pointer, "controller"))
+	}
+
+	var material []string
+	if method.PublicKeyJwk != nil {
+		material = append(material, "publicKeyJwk")
+	}
+	if method.PublicKeyMultibase != "" {
+		material = append(material, "publicKeyMultibase")
+	}
+	for _, name := range verificationMaterialProperties {
+		if value, ok := method.Properties[name]; ok && value != nil {
+			material = append(material, name)
+		}
+	}
+
+	switch {
+	case len(material) == 0:
+		v.add(ValidationMissingKey, pointer, "verification method has no verification material, "+
+			"ex- a publicKeyJwk or a publicKeyMultibase")
+	case len(material) > 1:
+		v.add(ValidationMultipleKeys, pointer, "verification method has more than one verification material - %s",
+			strings.Join(material, ", "))
+	}
+}
+
+// verificationMaterialProperties are the properties of a verification method, other than publicKeyJwk and
+// publicKeyMultibase, that are registered to hold its verification material. They are deprecated, or specific
+// to blockchains, but most deployed documents use them, ex- publicKeyBase58 in Ed25519VerificationKey2018.
+// https://www.w3.org/TR/did-spec-registries/#verification-method-properties
+var verificationMaterialProperties = []string{
+	"publicKeyBase58",
+	"publicKeyHex",
+	"publicKeyPem",
+	"blockchainAccountId",
+	"ethereumAddress",
+}
+
+// service checks a service at pointer
+func (v *validator) service(service *Service, pointer string) {
+	idPointer := appendPointer(pointer, "id")
+
+	if service.ID == "" {
+		v.add(ValidationServiceMissingID, pointer, "service does not have an id")
+		return
+	}
+
+	if ref, err := ParseReference(service.ID); err == nil {
+		if id := v.doc.ResolveReference(ref); !v.services.Add(id) {
+			v.add(ValidationDuplicateServiceID, idPointer, "another service has the id %s", id)
+		}
+		return
+	}
+
+	if u, err := url.Parse(service.ID); err != nil || !u.IsAbs() {
+		v.add(ValidationServiceIDNotURI, idPointer, "id %q of a service is not a URI", service.ID)
+		return
+	}
+
+	if v.serviceURIs == nil {
+		v.serviceURIs = make(map[string]bool)
+	}
+	if v.serviceURIs[service.ID] {
+		v.add(ValidationDuplicateServiceID, idPointer, "another service has the id %s", service.ID)
+	}
+	v.serviceURIs[service.ID] = true
+}
+
+// setPointer returns the JSON pointer of the element at index i of a set with n elements at pointer,
+// a set with a single element is represented by that element
+func setPointer(pointer string, i, n int) string {
+	if n == 1 {
+		return pointer
+	}
+	return appendPointer(pointer, i)
+}
@@ -0,0 +1,143 @@
+package did
+
+import (
+	"testing"
+)
+
+func TestDocumentValidate(t *testing.T) {
+	doc := testDocument(t)
+	assert(t, []ValidationError{{Code: ValidationMethodNotFound, Pointer: "/keyAgreement/0",
+		Message: "keyAgreement refers to did:example:123#missing, which is not in the document"}}, doc.Validate())
+
+	doc.KeyAgreement = nil
+	assert(t, []ValidationError(nil), doc.Validate())
+
+	tests := []struct {
+		name     string
+		change   func(doc *Document)
+		code     ValidationCode
+		pointer  string
+		expected int
+	}{
+		{"missing id", func(doc *Document) { doc.ID = nil }, ValidationMissingID, "", 3},
+		{"id is a URL", func(doc *Document) { doc.ID = mustParse(t, "did:example:123#a") }, ValidationIDIsURL, "/id", 1},
+		{"id is relative", func(doc *Document) { doc.ID = &DID{Fragment: "a"} }, ValidationIDIsURL, "/id", 3},
+		{"single controller", func(doc *Document) { doc.Controller = []*DID{mustParse(t, "did:example:1/path")} },
+			ValidationControllerNotDID, "/controller", 1},
+		{"controllers", func(doc *Document) {
+			doc.Controller = []*DID{mustParse(t, "did:example:1"), mustParse(t, "did:example:1?q=1")}
+		}, ValidationControllerNotDID, "/controller/1", 1},
+		{"method controller", func(doc *Document) {
+			doc.VerificationMethod[1].Controller = mustParse(t, "did:example:123#key-1")
+		}, ValidationControllerNotDID, "/verificationMethod/1/controller", 1},
+		{"method without id", func(doc *Document) { doc.VerificationMethod[1].ID = nil },
+			ValidationMethodMissingID, "/verificationMethod/1", 2},
+		{"method id is a DID", func(doc *Document) { doc.VerificationMethod[1].ID = mustParse(t, "did:example:456") },
+			ValidationMethodIDNotURL, "/verificationMethod/1/id", 2},
+		{"method id resolves to a DID", func(doc *Document) { doc.VerificationMethod[1].ID = &DID{} },
+			ValidationMethodIDNotURL, "/verificationMethod/1/id", 2},
+		{"duplicate method id", func(doc *Document) {
+			doc.VerificationMethod[1].ID = mustParse(t, "did:example:123#key-1")
+		}, ValidationDuplicateMethodID, "/verificationMethod/1/id", 2},
+		{"duplicate embedded method id", func(doc *Document) {
+			doc.Authentication[1].Method.ID = &DID{Fragment: "key-2"}
+		}, ValidationDuplicateMethodID, "/authentication/1/id", 1},
+		{"missing key", func(doc *Document) { doc.VerificationMethod[0].PublicKeyMultibase = "" },
+			ValidationMissingKey, "/verificationMethod/0", 1},
+		{"multiple keys", func(doc *Document) { doc.Authentication[1].Method.PublicKeyJwk = map[string]interface{}{} },
+			ValidationMultipleKeys, "/authentication/1", 1},
+		{"jwk and base58", func(doc *Document) {
+			doc.VerificationMethod[1].Properties = map[string]interface{}{"publicKeyBase58": "H3C2"}
+		}, ValidationMultipleKeys, "/verificationMethod/1", 1},
+		{"base58 and blockchain account", func(doc *Document) {
+			doc.VerificationMethod[0].PublicKeyMultibase = ""
+			doc.VerificationMethod[0].Properties = map[string]interface{}{"publicKeyBase58": "H3C2",
+				"blockchainAccountId": "eip155:1:0xab16a96D359eC26a11e2C2b3d8f8B8942d5Bfcdb"}
+		}, ValidationMultipleKeys, "/verificationMethod/0", 1},
+		{"null base58", func(doc *Document) {
+			doc.VerificationMethod[0].PublicKeyMultibase = ""
+			doc.VerificationMethod[0].Properties = map[string]interface{}{"publicKeyBase58": nil}
+		}, ValidationMissingKey, "/verificationMethod/0", 1},
+		{"method not found", func(doc *Document) {
+			doc.CapabilityInvocation = []RelationshipEntry{{Reference: &DID{Fragment: "key-3"}}}
+		}, ValidationMethodNotFound, "/capabilityInvocation/0", 1},
+		{"service without id", func(doc *Document) { doc.Service[0].ID = "" },
+			ValidationServiceMissingID, "/service/0", 1},
+		{"service id is not a URI", func(doc *Document) { doc.Service[1].ID = "linked domains" },
+			ValidationServiceIDNotURI, "/service/1/id", 1},
+		{"service id is not valid", func(doc *Document) { doc.Service[1].ID = "https://example.com/%zz" },
+			ValidationServiceIDNotURI, "/service/1/id", 1},
+		{"duplicate service id", func(doc *Document) { doc.Service[1].ID = "did:example:123#messaging" },
+			ValidationDuplicateServiceID, "/service/1/id", 1},
+		{"duplicate service URI", func(doc *Document) {
+			doc.Service = append(doc.Service, Service{ID: "https://example.com/linked"})
+		}, ValidationDuplicateServiceID, "/service/2/id", 1},
+	}
+
+	for _, test := range tests {
+		doc := testDocument(t)
+		doc.KeyAgreement = nil
+		test.change(doc)
+
+		errs := doc.Validate()
+		if len(errs) != test.expected {
+			t.Errorf("Test: %s Expected %d errors, got: %v", test.name, test.expected, errs)
+			continue
+		}
+		assert(t, test.code, errs[0].Code, "Test: %s Errors: %v", test.name, errs)
+		assert(t, test.pointer, errs[0].Pointer, "Test: %s Errors: %v", test.name, errs)
+	}
+
+	// relative method ids cannot be resolved without the id of the document
+	doc = testDocument(t)
+	doc.ID = nil
+	doc.KeyAgreement = nil
+	errs := doc.Validate()
+	if len(errs) < 2 {
+		t.Fatalf("Expected at least 2 errors, got: %v", errs)
+	}
+	assert(t, ValidationMethodIDUnresolvable, errs[1].Code)
+	assert(t, "/verificationMethod/0/id", errs[1].Pointer)
+	assert(t, `did: method-id-unresolvable: id #key-1 is relative, and the DID document does not have a DID `+
+		`to resolve it against at "/verificationMethod/0/id"`, errs[1].Error())
+}
+
+func TestDocumentValidateMaterial(t *testing.T) {
+	// registered verification material in Properties, ex- publicKeyBase58 of Ed25519VerificationKey2018
+	for _, name := range verificationMaterialProperties {
+		doc := testDocument(t)
+		doc.KeyAgreement = nil
+		doc.VerificationMethod[0].PublicKeyMultibase = ""
+		doc.VerificationMethod[0].Properties = map[string]interface{}{name: "value"}
+		assert(t, []ValidationError(nil), doc.Validate(), "Property: %s", name)
+	}
+
+	doc, err := DecodeJSON([]byte(`{
+		"id": "did:example:123",
+		"verificationMethod": [{
+			"id": "#key-1",
+			"type": "Ed25519VerificationKey2018",
+			"controller": "did:example:123",
+			"publicKeyBase58": "H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV"
+		}, {
+			"id": "#key-2",
+			"type": "JsonWebKey2020",
+			"controller": "did:example:123",
+			"publicKeyJwk": {"kty": "OKP", "crv": "Ed25519", "x": "VCpo"},
+			"publicKeyBase58": "H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV"
+		}],
+		"authentication": ["#key-1"]
+	}`), DecodeOptions{Strict: true})
+	assert(t, nil, err)
+	assert(t, []ValidationError{{Code: ValidationMultipleKeys, Pointer: "/verificationMethod/1",
+		Message: "verification method has more than one verification material - publicKeyJwk, publicKeyBase58"}},
+		doc.Validate())
+}
+
+func TestDocumentValidateExamples(t *testing.T) {
+	for _, name := range []string{"example.json", "example-ld.json"} {
+		doc, err := DecodeJSON(readDocument(t, name), DecodeOptions{Strict: true})
+		assert(t, nil, err, "Document: %s", name)
+		assert(t, []ValidationError(nil), doc.Validate(), "Document: %s", name)
+	}
+}
@@ -0,0 +1,158 @@
+package did
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A WebID is the decoded method-specific-id of a did:web DID
+// https://w3c-ccg.github.io/did-method-web/
+type WebID struct {
+	// Host is the domain name or IPv4 address of the web server that hosts the DID document
+	Host string
+
+	// Port is the port of the web server, it is empty if the port is not specified
+	Port string
+
+	// Path is the list of decoded path segments of the DID document on the web server, it is empty if the
+	// DID document is at /.well-known/did.json
+	Path []string
+}
+
+// ParseWebID decodes the method-specific-id of a did:web DID, which is a host name with an optional
+// pct-encoded port, followed by `:` separated path segments.
+// from the grammar:
+//   web-did = "did:web:" domain-name [ "%3A" port ] *( ":" path-segment )
+func ParseWebID(id string) (*WebID, error) {
+	idstrings := strings.Split(id, ":")
+	host := idstrings[0]
+	web := &WebID{}
+
+	// a host name is made of ASCII letters, digits, `-` and `.`, checkHost reports any other ASCII character
+	for i := 0; i < len(host); i++ {
+		if host[i] >= 0x80 {
+			return nil, fmt.Errorf("did:web host has a character that is not ASCII at offset %d", i)
+		}
+	}
+
+	// the `:` before the port is pct-encoded, the hex digits may be in either case
+	if i := indexPort(host); i >= 0 {
+		host, web.Port = host[:i], host[i+3:]
+
+		port, err := strconv.Atoi(web.Port)
+		if err != nil || port < 1 || port > 65535 || web.Port[0] == '0' || web.Port[0] == '+' {
+			return nil, fmt.Errorf("did:web port is not a number between 1 and 65535 - %s", web.Port)
+		}
+	}
+
+	if err := checkHost(host); err != nil {
+		return nil, err
+	}
+	web.Host = host
+
+	for _, segment := range idstrings[1:] {
+		if segment == "" {
+			return nil, errors.New("did:web path segment is empty")
+		}
+
+		segment, err := unescape(segment, ComponentMethodSpecificID)
+		if err != nil {
+			return nil, err
+		}
+		if strings.ContainsRune(segment, '/') {
+			return nil, errors.New("did:web path segment must not contain `/`")
+		}
+		web.Path = append(web.Path, segment)
+	}
+
+	return web, nil
+}
+
+// indexPort returns the index of the pct-encoded `:` that separates a host from its port, or -1 if there is none
+func indexPort(host string) int {
+	for i := 0; i+3 <= len(host); i++ {
+		if host[i] == '%' && strings.EqualFold(host[i:i+3], "%3A") {
+			return i
+		}
+	}
+	return -1
+}
+
+// String encodes a WebID as the method-specific-id of a did:web DID
+func (w *WebID) String() string {
+	var buf strings.Builder
+
+	buf.WriteString(w.Host) // nolint, returned error is always nil
+	if w.Port != "" {
+		buf.WriteString("%3A")  // nolint, returned error is always nil
+		buf.WriteString(w.Port) // nolint, returned error is always nil
+	}
+	for _, segment := range w.Path {
+		buf.WriteByte(':')                       // nolint, returned error is always nil
+		buf.WriteString(EscapeIDString(segment)) // nolint, returned error is always nil
+	}
+
+	return buf.String()
+}
+
+// URL returns the HTTPS URL of the DID document that the DID resolves to
+// https://w3c-ccg.github.io/did-method-web/#read-resolve
+func (w *WebID) URL() string {
+	var buf strings.Builder
+
+	buf.WriteString("https://") // nolint, returned error is always nil
+	buf.WriteString(w.Host)     // nolint, returned error is always nil
+	if w.Port != "" {
+		buf.WriteByte(':')      // nolint, returned error is always nil
+		buf.WriteString(w.Port) // nolint, returned error is always nil
+	}
+
+	if len(w.Path) == 0 {
+		buf.WriteString("/.well-known") // nolint, returned error is always nil
+	}
+	for _, segment := range w.Path {
+		buf.WriteByte('/')                          // nolint, returned error is always nil
+		buf.WriteString(EscapePathSegment(segment)) // nolint, returned error is always nil
+	}
+	buf.WriteString("/did.json") // nolint, returned error is always nil
+
+	return buf.String()
+}
+
+// checkHost returns an error if host is not a domain name, or an IPv4 address which has the syntax of a domain name
+// from the grammar:
+//   domain-name = label *( "." label )
+//   label       = ALPHA / DIGIT [ *( ALPHA / DIGIT / "-" ) ALPHA / DIGIT ]
+// https://tools.ietf.org/html/rfc1123#section-2.1
+func checkHost(host string) error {
+	if host == "" {
+		return errors.New("did:web host is empty")
+	}
+	if len(host) > 253 {
+		return errors.New("did:web host is longer than 253 characters")
+	}
+
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 {
+			return fmt.Errorf("did:web host label must be between 1 and 63 characters long - %q", label)
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("did:web host label must not begin or end with `-` - %q", label)
+		}
+		for i := 0; i < len(label); i++ {
+			if isNotAlpha(label[i]) && isNotDigit(label[i]) && label[i] != '-' {
+				return fmt.Errorf("did:web host has a character that is not allowed - %c", label[i])
+			}
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	RegisterMethod("web", MethodSyntaxFunc(func(id string) (interface{}, error) {
+		return ParseWebID(id)
+	}))
+}
@@ -0,0 +1,65 @@
+package did
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseWebID(t *testing.T) {
+	tests := []struct {
+		id       string
+		expected *WebID
+		url      string
+	}{
+		{"w3c-ccg.github.io", &WebID{Host: "w3c-ccg.github.io"}, "https://w3c-ccg.github.io/.well-known/did.json"},
+		{"w3c-ccg.github.io:user:alice", &WebID{Host: "w3c-ccg.github.io", Path: []string{"user", "alice"}},
+			"https://w3c-ccg.github.io/user/alice/did.json"},
+		{"example.com%3A3000:user:alice", &WebID{Host: "example.com", Port: "3000", Path: []string{"user", "alice"}},
+			"https://example.com:3000/user/alice/did.json"},
+		{"localhost%3a8443", &WebID{Host: "localhost", Port: "8443"}, "https://localhost:8443/.well-known/did.json"},
+		{"example.com:a%20b", &WebID{Host: "example.com", Path: []string{"a b"}}, "https://example.com/a%20b/did.json"},
+	}
+
+	for _, test := range tests {
+		d, err := ParseWithOptions("did:web:"+test.id, ParseOptions{ValidateMethodSpecificID: true})
+		assert(t, nil, err, "Input: %s", test.id)
+
+		value, err := d.MethodSpecific()
+		assert(t, nil, err, "Input: %s", test.id)
+		assert(t, test.expected, value, "Input: %s", test.id)
+		assert(t, test.url, value.(*WebID).URL(), "Input: %s", test.id)
+
+		roundTrip, err := ParseWebID(value.(*WebID).String())
+		assert(t, nil, err, "Input: %s", test.id)
+		assert(t, test.expected, roundTrip, "Input: %s", test.id)
+	}
+
+	invalid := []string{
+		"",
+		"%3A3000",
+		"example.com%3A",
+		"example.com%3A0",
+		"example.com%3A65536",
+		"example.com%3A+80",
+		"example.com%3Aabc",
+		"example..com",
+		"-example.com",
+		"example-.com",
+		"exa_mple.com",
+		"exa%2Fmple.com",
+		"example.com::alice",
+		"example.com:a%2Fb",
+		"ɐ%3A",
+		"ɐ%3A80",
+		"exámple.com",
+		"example.com%3",
+	}
+
+	for _, id := range invalid {
+		_, err := ParseWebID(id)
+		assert(t, false, err == nil, "Input: %s", id)
+	}
+
+	_, err := ParseWithOptions("did:web:example..com", ParseOptions{ValidateMethodSpecificID: true})
+	assert(t, true, errors.Is(err, ErrInvalidMethodSpecificID))
+}